@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"scratch-note/utils"
+)
+
+// appendTimestampLayout formats the timestamp starting each appended line
+const appendTimestampLayout = "2006-01-02 15:04"
+
+// AppendOptions holds the options of the append command
+type AppendOptions struct {
+	// Ref is a path, list index or title fragment of the note
+	Ref string
+	// Last appends to the newest note instead of Ref
+	Last bool
+	// Text is appended; when empty it is read from standard input
+	Text string
+}
+
+// AppendNote appends a timestamped entry to the note selected by opts in
+// directory without opening an editor. The text comes from opts.Text, or
+// from input when that is empty. It returns the path of the note.
+func AppendNote(directory string, opts AppendOptions, input io.Reader, t time.Time) (string, error) {
+	text := opts.Text
+	if text == "" && input != nil {
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %v", err)
+		}
+		text = string(data)
+	}
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("nothing to append")
+	}
+
+	var path string
+	var err error
+	if opts.Last {
+		path, err = LastNote(directory, 1)
+	} else {
+		path, err = ResolveUniqueNote(directory, opts.Ref)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := appendToFile(path, appendEntry(text, t), 0); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// appendEntry formats text as a markdown list item stamped with t. Further
+// lines are indented to stay within the item.
+func appendEntry(text string, t time.Time) []byte {
+	text = strings.Trim(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	lines := strings.Split(text, "\n")
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "- %s %s\n", t.Format(appendTimestampLayout), lines[0])
+	for _, line := range lines[1:] {
+		if strings.TrimSpace(line) == "" {
+			b.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	return b.Bytes()
+}
+
+// appendToFile appends data to the file at path in a single write while
+// holding an exclusive lock, so concurrent appends never interleave. An
+// unterminated last line is ended first. flag may add os.O_CREATE.
+func appendToFile(path string, data []byte, flag int) error {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|flag, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open note: %v", err)
+	}
+	defer file.Close()
+
+	unlock, err := utils.LockFile(file)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat note: %v", err)
+	}
+	if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err != nil {
+			return fmt.Errorf("failed to read note: %v", err)
+		}
+		if last[0] != '\n' {
+			data = append([]byte("\n"), data...)
+		}
+	}
+
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write note: %v", err)
+	}
+	if err := file.Sync(); err != nil {
+		return fmt.Errorf("failed to write note: %v", err)
+	}
+	return nil
+}
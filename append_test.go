@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAppendNote(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-08-15_120000_retro.md", "# retro\n")
+	writeTestNoteContent(t, dir, "2025-08-16_143045_standup.md", "no newline")
+	at := time.Date(2025, 8, 16, 15, 4, 0, 0, time.Local)
+
+	path, err := AppendNote(dir, AppendOptions{Ref: "retro", Text: "follow up"}, nil, at)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filepath.Base(path) != "2025-08-15_120000_retro.md" {
+		t.Errorf("AppendNote() = %s, want 2025-08-15_120000_retro.md", filepath.Base(path))
+	}
+	content, _ := os.ReadFile(path)
+	if expected := "# retro\n- 2025-08-16 15:04 follow up\n"; string(content) != expected {
+		t.Errorf("Note content = %q, want %q", content, expected)
+	}
+
+	// --last reads stdin and ends the unterminated last line first
+	input := strings.NewReader("deployed\r\nrolled back\n\nall good\n")
+	path, err = AppendNote(dir, AppendOptions{Last: true}, input, at)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, _ = os.ReadFile(path)
+	expected := "no newline\n- 2025-08-16 15:04 deployed\n  rolled back\n\n  all good\n"
+	if string(content) != expected {
+		t.Errorf("Note content = %q, want %q", content, expected)
+	}
+}
+
+func TestAppendNoteErrors(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-15_120000_standup.md",
+		"2025-08-16_143045_standup.md",
+	)
+	at := time.Now()
+
+	tests := []struct {
+		name  string
+		opts  AppendOptions
+		input string
+	}{
+		{name: "empty text", opts: AppendOptions{Ref: "1"}, input: "  \n"},
+		{name: "unknown note", opts: AppendOptions{Ref: "retro", Text: "x"}},
+		{name: "ambiguous note", opts: AppendOptions{Ref: "standup", Text: "x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := AppendNote(dir, tt.opts, strings.NewReader(tt.input), at); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+
+	if _, err := AppendNote(t.TempDir(), AppendOptions{Last: true, Text: "x"}, nil, at); err == nil {
+		t.Error("Expected error without notes")
+	}
+}
+
+func TestAppendNoteConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestNoteContent(t, dir, "2025-08-16_143045_log.md", "")
+	at := time.Date(2025, 8, 16, 15, 4, 0, 0, time.Local)
+
+	const writers = 20
+	text := strings.Repeat("x", 4096)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			opts := AppendOptions{Ref: path, Text: fmt.Sprintf("%d %s", i, text)}
+			if _, err := AppendNote(dir, opts, nil, at); err != nil {
+				t.Errorf("Unexpected error: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	content, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(lines) != writers {
+		t.Fatalf("Note has %d lines, want %d", len(lines), writers)
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "- 2025-08-16 15:04 ") || !strings.HasSuffix(line, text) {
+			t.Errorf("Interleaved line: %.40q...", line)
+		}
+	}
+}
+
+func TestParseArgsAppend(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    Command
+		expectError bool
+	}{
+		{name: "note and text", args: []string{"scratch-note", "append", "retro", "follow", "up"}, expected: Command{Type: CommandTypeAppend, Append: AppendOptions{Ref: "retro", Text: "follow up"}}},
+		{name: "note only", args: []string{"scratch-note", "append", "retro"}, expected: Command{Type: CommandTypeAppend, Append: AppendOptions{Ref: "retro"}}},
+		{name: "last with text", args: []string{"scratch-note", "append", "done", "--last"}, expected: Command{Type: CommandTypeAppend, Append: AppendOptions{Last: true, Text: "done"}}},
+		{name: "last only", args: []string{"scratch-note", "append", "--last"}, expected: Command{Type: CommandTypeAppend, Append: AppendOptions{Last: true}}},
+		{name: "missing note", args: []string{"scratch-note", "append"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseArgs(tt.args)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", cmd)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cmd, tt.expected) {
+				t.Errorf("ParseArgs() = %+v, want %+v", cmd, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,774 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"scratch-note/config"
+)
+
+// CommandType represents the type of command to execute
+type CommandType int
+
+const (
+	CommandTypeCreate CommandType = iota
+	CommandTypeConfig
+	CommandTypeHelp
+	CommandTypeList
+	CommandTypeSearch
+	CommandTypeIndex
+	CommandTypeOpen
+	CommandTypeLast
+	CommandTypePick
+	CommandTypeDaily
+	CommandTypeAppend
+	CommandTypeMigrate
+	CommandTypeInit
+	CommandTypeNotebooks
+	CommandTypeTag
+	CommandTypeTags
+	CommandTypeDelete
+	CommandTypeTrash
+	CommandTypeRename
+	CommandTypeBacklinks
+	CommandTypeLinks
+)
+
+// Command represents a parsed command
+type Command struct {
+	Type  CommandType
+	Title string
+
+	// Topic is the subcommand to show help for (CommandTypeHelp only)
+	Topic string
+
+	Create    CreateOptions
+	Config    ConfigOptions
+	List      ListOptions
+	Search    SearchOptions
+	Index     IndexOptions
+	Open      OpenOptions
+	Pick      PickOptions
+	Daily     DailyOptions
+	Append    AppendOptions
+	Migrate   MigrateOptions
+	Init      InitOptions
+	Tag       TagOptions
+	Delete    DeleteOptions
+	Trash     TrashOptions
+	Rename    RenameOptions
+	Backlinks BacklinksOptions
+	Links     LinksOptions
+
+	// Overrides are configuration values given with the global flags, in
+	// the order they appeared
+	Overrides []config.Override
+}
+
+// CreateOptions holds the options of the new command
+type CreateOptions struct {
+	// Stdin reads the note content from standard input instead of an editor
+	Stdin bool
+	// Messages are written as the note content instead of launching an editor
+	Messages []string
+	// Template names the template to render into the note
+	Template string
+	// Vars are extra template variables given as --var key=value
+	Vars map[string]string
+	// Tags are written to the note's frontmatter, normalized
+	Tags []string
+}
+
+// ConfigOptions holds the options of the config command
+type ConfigOptions struct {
+	// Action is the config sub-action: "edit", "validate" or "show"
+	Action string
+	// Origin shows where each value of "show" came from
+	Origin bool
+}
+
+// stringSliceFlag is a flag.Value collecting every occurrence of a repeatable flag
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// subcommand describes a CLI subcommand and how its arguments are parsed
+type subcommand struct {
+	Name    string
+	Aliases []string
+	Type    CommandType
+	Group   string
+	Usage   string
+	Summary string
+
+	// Flags registers the command's flags on fs, storing values into cmd
+	Flags func(fs *flag.FlagSet, cmd *Command)
+	// Args validates the positional arguments and stores them into cmd
+	Args func(cmd *Command, args []string) error
+}
+
+// Command groups in the order they appear in the usage message
+const (
+	groupNotes  = "Notes"
+	groupConfig = "Configuration"
+	groupOther  = "Other"
+)
+
+var commandGroups = []string{groupNotes, groupConfig, groupOther}
+
+// subcommands returns all subcommands known to the CLI
+func subcommands() []subcommand {
+	return []subcommand{
+		{
+			Name:    "new",
+			Type:    CommandTypeCreate,
+			Group:   groupNotes,
+			Usage:   "new [flags] [title]",
+			Summary: "Create a new timestamped note",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Create.Stdin, "stdin", false, "read the note content from standard input")
+				fs.Var((*stringSliceFlag)(&cmd.Create.Messages), "m", "use `text` as the note content (repeatable)")
+				fs.Var((*stringSliceFlag)(&cmd.Create.Messages), "message", "same as -m")
+				fs.StringVar(&cmd.Create.Template, "template", "", "start the note from template `name`")
+				fs.Var((*varsFlag)(&cmd.Create.Vars), "var", "set template variable as `key=value` (repeatable)")
+				fs.Var((*tagFlag)(&cmd.Create.Tags), "t", "tag the note with `tag` (repeatable)")
+				fs.Var((*tagFlag)(&cmd.Create.Tags), "tag", "same as -t")
+			},
+			Args: func(cmd *Command, args []string) error {
+				if len(args) > 1 {
+					return fmt.Errorf("too many arguments")
+				}
+				if cmd.Create.Stdin && len(cmd.Create.Messages) > 0 {
+					return fmt.Errorf("--stdin and -m cannot be used together")
+				}
+				if len(args) == 1 {
+					cmd.Title = args[0]
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "daily",
+			Type:    CommandTypeDaily,
+			Group:   groupNotes,
+			Usage:   "daily [--yesterday | --date date]",
+			Summary: "Add an entry to the note of the day",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Daily.Yesterday, "yesterday", false, "use yesterday's note")
+				fs.Var(dateFlag{t: &cmd.Daily.Date}, "date", "use the note of `date` (YYYY-MM-DD)")
+			},
+			Args: func(cmd *Command, args []string) error {
+				if len(args) > 0 {
+					return fmt.Errorf("too many arguments")
+				}
+				if cmd.Daily.Yesterday && !cmd.Daily.Date.IsZero() {
+					return fmt.Errorf("--yesterday and --date cannot be used together")
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "append",
+			Type:    CommandTypeAppend,
+			Group:   groupNotes,
+			Usage:   "append <note> [text] | append --last [text]",
+			Summary: "Append a timestamped line to a note without an editor",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Append.Last, "last", false, "append to the newest note")
+			},
+			Args: func(cmd *Command, args []string) error {
+				if !cmd.Append.Last {
+					if len(args) == 0 {
+						return fmt.Errorf("missing note to append to")
+					}
+					cmd.Append.Ref = args[0]
+					args = args[1:]
+				}
+				cmd.Append.Text = strings.Join(args, " ")
+				return nil
+			},
+		},
+		{
+			Name:    "rename",
+			Aliases: []string{"mv"},
+			Type:    CommandTypeRename,
+			Group:   groupNotes,
+			Usage:   "rename <note> <new title>",
+			Summary: "Retitle a note, keeping its timestamp and updating links to it",
+			Args: func(cmd *Command, args []string) error {
+				if len(args) < 2 {
+					return fmt.Errorf("missing note or new title")
+				}
+				cmd.Rename.Ref = args[0]
+				cmd.Rename.Title = strings.Join(args[1:], " ")
+				return nil
+			},
+		},
+		{
+			Name:    "backlinks",
+			Type:    CommandTypeBacklinks,
+			Group:   groupNotes,
+			Usage:   "backlinks <note> [--json]",
+			Summary: "List the [[wiki-links]] pointing to a note",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Backlinks.JSON, "json", false, "print links as JSON")
+			},
+			Args: func(cmd *Command, args []string) error {
+				if len(args) != 1 {
+					return fmt.Errorf("expected exactly one note")
+				}
+				cmd.Backlinks.Ref = args[0]
+				return nil
+			},
+		},
+		{
+			Name:    "links",
+			Type:    CommandTypeLinks,
+			Group:   groupNotes,
+			Usage:   "links [check] [--json]",
+			Summary: "Report broken and ambiguous links and orphan notes",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Links.JSON, "json", false, "print the report as JSON")
+			},
+			Args: func(cmd *Command, args []string) error {
+				cmd.Links.Action = LinksActionCheck
+				if len(args) > 0 {
+					cmd.Links.Action, args = args[0], args[1:]
+				}
+				if cmd.Links.Action != LinksActionCheck {
+					return fmt.Errorf("unknown links action: %s", cmd.Links.Action)
+				}
+				if len(args) > 0 {
+					return fmt.Errorf("too many arguments")
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "delete",
+			Aliases: []string{"rm"},
+			Type:    CommandTypeDelete,
+			Group:   groupNotes,
+			Usage:   "delete <note>...",
+			Summary: "Move notes to the trash",
+			Args: func(cmd *Command, args []string) error {
+				if len(args) == 0 {
+					return fmt.Errorf("missing note to delete")
+				}
+				cmd.Delete.Refs = args
+				return nil
+			},
+		},
+		{
+			Name:    "trash",
+			Type:    CommandTypeTrash,
+			Group:   groupNotes,
+			Usage:   "trash [list | restore <n>... | empty [--older-than 30d]]",
+			Summary: "List, restore or permanently delete notes in the trash",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.Var(ageFlag{&cmd.Trash.OlderThan}, "older-than", "with empty, only notes deleted longer than `age` ago, e.g. 30d")
+			},
+			Args: func(cmd *Command, args []string) error {
+				cmd.Trash.Action = TrashActionList
+				if len(args) > 0 {
+					cmd.Trash.Action, args = args[0], args[1:]
+				}
+				switch cmd.Trash.Action {
+				case TrashActionList, TrashActionEmpty:
+					if len(args) > 0 {
+						return fmt.Errorf("too many arguments")
+					}
+				case TrashActionRestore:
+					if len(args) == 0 {
+						return fmt.Errorf("missing note to restore")
+					}
+					cmd.Trash.Refs = args
+				default:
+					return fmt.Errorf("unknown trash action: %s", cmd.Trash.Action)
+				}
+				if cmd.Trash.OlderThan > 0 && cmd.Trash.Action != TrashActionEmpty {
+					return fmt.Errorf("--older-than can only be used with empty")
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "list",
+			Aliases: []string{"ls"},
+			Type:    CommandTypeList,
+			Group:   groupNotes,
+			Usage:   "list [flags]",
+			Summary: "List notes in the scratch-note directory",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.StringVar(&cmd.List.Sort, "sort", SortCreated, "sort by `order`: created, title or modified")
+				fs.BoolVar(&cmd.List.Reverse, "reverse", false, "reverse the sort order")
+				fs.Var(dateFlag{t: &cmd.List.Since}, "since", "only notes created on or after `date`")
+				fs.Var(dateFlag{t: &cmd.List.Until, endOfDay: true}, "until", "only notes created on or before `date`")
+				fs.IntVar(&cmd.List.Limit, "limit", 0, "show at most `n` notes (0 for all)")
+				fs.StringVar(&cmd.List.Format, "format", FormatTable, "output `format`: table, json or plain")
+				fs.Var((*tagFilterFlag)(&cmd.List.Tags), "tag", "only notes tagged `tag`, or not tagged with !tag (repeatable)")
+			},
+			Args: func(cmd *Command, args []string) error {
+				if len(args) > 0 {
+					return fmt.Errorf("too many arguments")
+				}
+				return validateListOptions(cmd.List)
+			},
+		},
+		{
+			Name:    "tag",
+			Type:    CommandTypeTag,
+			Group:   groupNotes,
+			Usage:   "tag add|rm <note> <tag>...",
+			Summary: "Add tags to or remove tags from a note",
+			Args: func(cmd *Command, args []string) error {
+				if len(args) == 0 {
+					return fmt.Errorf("missing tag action (add or rm)")
+				}
+				switch args[0] {
+				case TagActionAdd, TagActionRemove:
+					cmd.Tag.Action = args[0]
+				default:
+					return fmt.Errorf("unknown tag action: %s", args[0])
+				}
+				if len(args) < 3 {
+					return fmt.Errorf("missing note or tags")
+				}
+				cmd.Tag.Ref = args[1]
+				tags, err := parseTags(args[2:])
+				cmd.Tag.Tags = tags
+				return err
+			},
+		},
+		{
+			Name:    "tags",
+			Type:    CommandTypeTags,
+			Group:   groupNotes,
+			Usage:   "tags",
+			Summary: "Show every tag and the number of notes having it",
+		},
+		{
+			Name:    "open",
+			Type:    CommandTypeOpen,
+			Group:   groupNotes,
+			Usage:   "open <query|index|path>",
+			Summary: "Open a note by title fragment, list index or path",
+			Args: func(cmd *Command, args []string) error {
+				if len(args) == 0 {
+					return fmt.Errorf("missing note to open")
+				}
+				cmd.Open.Ref = strings.Join(args, " ")
+				return nil
+			},
+		},
+		{
+			Name:    "last",
+			Type:    CommandTypeLast,
+			Group:   groupNotes,
+			Usage:   "last [n]",
+			Summary: "Open the newest note, or the n-th newest",
+			Args: func(cmd *Command, args []string) error {
+				n, err := parseCount(args)
+				if err != nil {
+					return err
+				}
+				cmd.Open.Last = n
+				return nil
+			},
+		},
+		{
+			Name:    "pick",
+			Type:    CommandTypePick,
+			Group:   groupNotes,
+			Usage:   "pick [--print] [query]",
+			Summary: "Choose a note with the interactive fuzzy finder",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Pick.Print, "print", false, "print the selected path instead of opening it")
+			},
+			Args: func(cmd *Command, args []string) error {
+				cmd.Pick.Query = strings.Join(args, " ")
+				return nil
+			},
+		},
+		{
+			Name:    "search",
+			Aliases: []string{"grep"},
+			Type:    CommandTypeSearch,
+			Group:   groupNotes,
+			Usage:   "search [flags] <query>",
+			Summary: "Search the content of all notes",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Search.Regex, "regex", false, "treat the query as a regular expression")
+				fs.BoolVar(&cmd.Search.Regex, "E", false, "same as --regex")
+				fs.BoolVar(&cmd.Search.JSON, "json", false, "print hits as JSON")
+				fs.BoolVar(&cmd.Search.NoColor, "no-color", false, "do not highlight matches")
+				fs.IntVar(&cmd.Search.Workers, "workers", 0, "read at most `n` notes at once (0 for one per CPU)")
+				fs.BoolVar(&cmd.Search.NoIndex, "no-index", false, "scan every note instead of using the search index")
+			},
+			Args: func(cmd *Command, args []string) error {
+				if len(args) == 0 {
+					return fmt.Errorf("missing search query")
+				}
+				if cmd.Search.Workers < 0 {
+					return fmt.Errorf("workers must not be negative")
+				}
+				cmd.Search.Query = strings.Join(args, " ")
+				return nil
+			},
+		},
+		{
+			Name:    "index",
+			Type:    CommandTypeIndex,
+			Group:   groupNotes,
+			Usage:   "index [--rebuild]",
+			Summary: "Build or update the search index",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Index.Rebuild, "rebuild", false, "discard the existing index and index every note again")
+			},
+		},
+		{
+			Name:    "migrate",
+			Type:    CommandTypeMigrate,
+			Group:   groupNotes,
+			Usage:   "migrate [--dry-run]",
+			Summary: "Move notes into the directories of the configured layout",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Migrate.DryRun, "dry-run", false, "only show which notes would be moved")
+			},
+		},
+		{
+			Name:    "init",
+			Type:    CommandTypeInit,
+			Group:   groupConfig,
+			Usage:   "init [--force]",
+			Summary: "Set up the notes directory and configuration file",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Init.Force, "force", false, "replace an existing configuration file")
+			},
+		},
+		{
+			Name:    "notebooks",
+			Type:    CommandTypeNotebooks,
+			Group:   groupConfig,
+			Usage:   "notebooks",
+			Summary: "List the configured notebooks",
+		},
+		{
+			Name:    "config",
+			Type:    CommandTypeConfig,
+			Group:   groupConfig,
+			Usage:   "config [edit|validate|show [--origin]]",
+			Summary: "Create, edit, validate or show the configuration",
+			Flags: func(fs *flag.FlagSet, cmd *Command) {
+				fs.BoolVar(&cmd.Config.Origin, "origin", false, "with show, print where each value came from")
+			},
+			Args: func(cmd *Command, args []string) error {
+				cmd.Config.Action = "edit"
+				if len(args) > 1 {
+					return fmt.Errorf("too many arguments")
+				}
+				if len(args) == 1 {
+					switch args[0] {
+					case "edit", "validate", "show":
+						cmd.Config.Action = args[0]
+					default:
+						return fmt.Errorf("unknown config action: %s", args[0])
+					}
+				}
+				if cmd.Config.Origin && cmd.Config.Action != "show" {
+					return fmt.Errorf("--origin can only be used with show")
+				}
+				return nil
+			},
+		},
+		{
+			Name:    "help",
+			Type:    CommandTypeHelp,
+			Group:   groupOther,
+			Usage:   "help [command]",
+			Summary: "Show help for scratch-note or a command",
+			Args: func(cmd *Command, args []string) error {
+				if len(args) > 1 {
+					return fmt.Errorf("too many arguments")
+				}
+				if len(args) == 1 {
+					if _, ok := lookupSubcommand(args[0]); !ok {
+						return fmt.Errorf("unknown command: %s", args[0])
+					}
+					cmd.Topic = args[0]
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// lookupSubcommand finds a subcommand by name or alias
+func lookupSubcommand(name string) (subcommand, bool) {
+	for _, sc := range subcommands() {
+		if sc.Name == name {
+			return sc, true
+		}
+		for _, alias := range sc.Aliases {
+			if alias == name {
+				return sc, true
+			}
+		}
+	}
+	return subcommand{}, false
+}
+
+// overrideFlag is a flag.Value setting a configuration key, such as --editor
+type overrideFlag struct {
+	key       string
+	flag      string
+	overrides *[]config.Override
+}
+
+func (f overrideFlag) String() string {
+	return ""
+}
+
+func (f overrideFlag) Set(value string) error {
+	*f.overrides = append(*f.overrides, config.Override{Key: f.key, Value: value, Flag: f.flag})
+	return nil
+}
+
+// setFlag is the flag.Value of --set key=value
+type setFlag struct {
+	overrides *[]config.Override
+}
+
+func (f setFlag) String() string {
+	return ""
+}
+
+func (f setFlag) Set(value string) error {
+	key, v, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	*f.overrides = append(*f.overrides, config.Override{Key: strings.TrimSpace(key), Value: v, Flag: "--set " + strings.TrimSpace(key)})
+	return nil
+}
+
+// globalFlags registers the flags every command accepts, which override the
+// configuration, storing them into overrides
+func globalFlags(fs *flag.FlagSet, overrides *[]config.Override) {
+	fs.Var(overrideFlag{"notebook", "--notebook", overrides}, "notebook", "use the notebook called `name`")
+	fs.Var(overrideFlag{"scratch_note_dir", "--dir", overrides}, "dir", "use `directory` as the notes directory")
+	fs.Var(overrideFlag{"editor", "--editor", overrides}, "editor", "use `command` as the editor")
+	fs.Var(setFlag{overrides}, "set", "set configuration `key=value` (repeatable)")
+}
+
+// isGlobalFlag reports whether arg is one of the global flags
+func isGlobalFlag(arg string) bool {
+	fs := flag.NewFlagSet("", flag.ContinueOnError)
+	globalFlags(fs, new([]config.Override))
+	name, _, _ := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+	return strings.HasPrefix(arg, "-") && fs.Lookup(name) != nil
+}
+
+// peelGlobalFlags parses the global flags at the start of args into
+// overrides and returns the arguments after them, which may hold the flags
+// of the command
+func peelGlobalFlags(args []string, overrides *[]config.Override) ([]string, error) {
+	fs := flag.NewFlagSet("scratch-note", flag.ContinueOnError)
+	globalFlags(fs, overrides)
+	for len(args) > 0 && isGlobalFlag(args[0]) {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[0], "-"), "=")
+		args = args[1:]
+		if !hasValue {
+			if len(args) == 0 {
+				return nil, fmt.Errorf("flag needs an argument: -%s", name)
+			}
+			value, args = args[0], args[1:]
+		}
+		if err := fs.Set(name, value); err != nil {
+			return nil, fmt.Errorf("invalid value %q for flag -%s: %v", value, name, err)
+		}
+	}
+	return args, nil
+}
+
+// ParseArgs parses command line arguments
+func ParseArgs(args []string) (Command, error) {
+	if len(args) <= 1 {
+		return Command{Type: CommandTypeCreate, Title: ""}, nil
+	}
+
+	// Global flags may also come before the command
+	var overrides []config.Override
+	rest, err := peelGlobalFlags(args[1:], &overrides)
+	if err != nil {
+		return Command{}, err
+	}
+	args = append(args[:1:1], rest...)
+
+	cmd, err := parseCommand(args)
+	if err != nil || cmd.Type == CommandTypeHelp {
+		return cmd, err
+	}
+	cmd.Overrides = append(overrides, cmd.Overrides...)
+	return cmd, nil
+}
+
+// parseCommand parses the command and its arguments in args[1:]
+func parseCommand(args []string) (Command, error) {
+	if len(args) <= 1 {
+		return Command{Type: CommandTypeCreate, Title: ""}, nil
+	}
+
+	// Legacy flags kept for compatibility with the original CLI
+	switch args[1] {
+	case "--config":
+		return parseSubcommand("config", args[2:])
+	case "--help", "-h":
+		return parseSubcommand("help", args[2:])
+	}
+
+	if _, ok := lookupSubcommand(args[1]); ok {
+		return parseSubcommand(args[1], args[2:])
+	}
+
+	// Anything else is shorthand for `new`, e.g. scratch-note "title"
+	return parseSubcommand("new", args[1:])
+}
+
+// parseSubcommand parses args with the flag set and argument rules of the named subcommand
+func parseSubcommand(name string, args []string) (Command, error) {
+	sc, ok := lookupSubcommand(name)
+	if !ok {
+		return Command{}, fmt.Errorf("unknown command: %s", name)
+	}
+
+	cmd := Command{Type: sc.Type}
+	fs := newFlagSet(sc, &cmd)
+	globalFlags(fs, &cmd.Overrides)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return Command{Type: CommandTypeHelp, Topic: sc.Name}, nil
+		}
+		return Command{}, err
+	}
+
+	if sc.Args != nil {
+		err = sc.Args(&cmd, positional)
+	} else if len(positional) > 0 {
+		err = fmt.Errorf("too many arguments")
+	}
+	if err != nil {
+		return Command{}, err
+	}
+
+	return cmd, nil
+}
+
+// newFlagSet builds the flag set of a subcommand bound to cmd
+func newFlagSet(sc subcommand, cmd *Command) *flag.FlagSet {
+	fs := flag.NewFlagSet(sc.Name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if sc.Flags != nil {
+		sc.Flags(fs, cmd)
+	}
+	return fs
+}
+
+// parseFlags parses fs from args, allowing flags to appear before or after
+// positional arguments, and returns the positional arguments in order.
+// Everything after a "--" terminator is treated as positional.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+
+		consumed := len(args) - len(rest)
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// writeUsage writes the grouped usage message for all subcommands
+func writeUsage(w io.Writer) {
+	fmt.Fprintln(w, "scratch-note - A simple terminal-based note-taking tool")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "USAGE:")
+	fmt.Fprintln(w, "  scratch-note [title]              Create new note (shorthand for 'new')")
+	fmt.Fprintln(w, "  scratch-note <command> [flags]    Run a command")
+
+	for _, group := range commandGroups {
+		fmt.Fprintln(w, "")
+		fmt.Fprintf(w, "%s COMMANDS:\n", strings.ToUpper(group))
+		for _, sc := range subcommands() {
+			if sc.Group == group {
+				fmt.Fprintf(w, "  %-32s %s\n", sc.Usage, sc.Summary)
+			}
+		}
+	}
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "GLOBAL FLAGS:")
+	fmt.Fprintln(w, "  --notebook name                  Use a notebook from the configuration")
+	fmt.Fprintln(w, "  --dir directory                  Use another notes directory")
+	fmt.Fprintln(w, "  --editor command                 Use another editor")
+	fmt.Fprintln(w, "  --set key=value                  Override any configuration key")
+
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "EXAMPLES:")
+	fmt.Fprintln(w, "  scratch-note                      # Creates: 2025-08-16_143045.md")
+	fmt.Fprintln(w, "  scratch-note \"meeting notes\"      # Creates: 2025-08-16_143045_meeting-notes.md")
+	fmt.Fprintln(w, "  kubectl logs pod | scratch-note \"incident\"   # Saves stdin without an editor")
+	fmt.Fprintln(w, "  scratch-note -t bug -t infra \"disk full\"   # Creates a note tagged bug and infra")
+	fmt.Fprintln(w, "  scratch-note list --tag infra --tag '!done'")
+	fmt.Fprintln(w, "  scratch-note open meeting         # Opens the newest note titled like 'meeting'")
+	fmt.Fprintln(w, "  vim \"$(scratch-note pick --print)\"   # Fuzzy-find a note for another tool")
+	fmt.Fprintln(w, "  scratch-note search 'deploy \"error budget\" -staging'")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "CONFIGURATION:")
+	fmt.Fprintln(w, "  Config file: ~/.config/scratch-note/config.yaml")
+	fmt.Fprintln(w, "  Also read: /etc/scratch-note/config.yaml, .scratch-note.yaml in the")
+	fmt.Fprintln(w, "  current directory or a parent, and SCRATCH_NOTE_* environment variables")
+	fmt.Fprintln(w, "  Run 'scratch-note config' to create or edit configuration")
+	fmt.Fprintln(w, "  Run 'scratch-note config show --origin' to see where each value comes from")
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "Run 'scratch-note help <command>' for details on a command.")
+	fmt.Fprintln(w, "For more information, visit: https://github.com/your-repo/scratch-note")
+}
+
+// writeCommandUsage writes the usage message of a single subcommand
+func writeCommandUsage(w io.Writer, sc subcommand) {
+	fmt.Fprintf(w, "Usage: scratch-note %s\n", sc.Usage)
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, sc.Summary)
+
+	fs := newFlagSet(sc, &Command{})
+	hasFlags := false
+	fs.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(w, "")
+		fmt.Fprintln(w, "FLAGS:")
+		fs.SetOutput(w)
+		fs.PrintDefaults()
+	}
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"scratch-note/config"
+)
+
+func TestParseArgsSubcommands(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expectedCmd Command
+		expectError bool
+	}{
+		{
+			name:        "new without title",
+			args:        []string{"scratch-note", "new"},
+			expectedCmd: Command{Type: CommandTypeCreate},
+		},
+		{
+			name:        "new with title",
+			args:        []string{"scratch-note", "new", "meeting notes"},
+			expectedCmd: Command{Type: CommandTypeCreate, Title: "meeting notes"},
+		},
+		{
+			name:        "new with title that is a command name",
+			args:        []string{"scratch-note", "new", "config"},
+			expectedCmd: Command{Type: CommandTypeCreate, Title: "config"},
+		},
+		{
+			name:        "new with messages",
+			args:        []string{"scratch-note", "new", "-m", "first", "title", "--message", "second"},
+			expectedCmd: Command{Type: CommandTypeCreate, Title: "title", Create: CreateOptions{Messages: []string{"first", "second"}}},
+		},
+		{
+			name:        "shorthand with stdin flag",
+			args:        []string{"scratch-note", "incident", "--stdin"},
+			expectedCmd: Command{Type: CommandTypeCreate, Title: "incident", Create: CreateOptions{Stdin: true}},
+		},
+		{
+			name:        "new with template and variables",
+			args:        []string{"scratch-note", "standup", "--template", "daily", "--var", "team=infra", "--var", "sprint=12"},
+			expectedCmd: Command{Type: CommandTypeCreate, Title: "standup", Create: CreateOptions{Template: "daily", Vars: map[string]string{"team": "infra", "sprint": "12"}}},
+		},
+		{
+			name:        "new with malformed variable",
+			args:        []string{"scratch-note", "new", "--var", "team"},
+			expectError: true,
+		},
+		{
+			name:        "stdin and message together",
+			args:        []string{"scratch-note", "new", "--stdin", "-m", "text"},
+			expectError: true,
+		},
+		{
+			name:        "new with too many arguments",
+			args:        []string{"scratch-note", "new", "a", "b"},
+			expectError: true,
+		},
+		{
+			name:        "config command",
+			args:        []string{"scratch-note", "config"},
+			expectedCmd: Command{Type: CommandTypeConfig, Config: ConfigOptions{Action: "edit"}},
+		},
+		{
+			name:        "legacy config flag",
+			args:        []string{"scratch-note", "--config"},
+			expectedCmd: Command{Type: CommandTypeConfig, Config: ConfigOptions{Action: "edit"}},
+		},
+		{
+			name:        "config edit",
+			args:        []string{"scratch-note", "config", "edit"},
+			expectedCmd: Command{Type: CommandTypeConfig, Config: ConfigOptions{Action: "edit"}},
+		},
+		{
+			name:        "config validate",
+			args:        []string{"scratch-note", "config", "validate"},
+			expectedCmd: Command{Type: CommandTypeConfig, Config: ConfigOptions{Action: "validate"}},
+		},
+		{
+			name:        "config show with origin",
+			args:        []string{"scratch-note", "config", "show", "--origin"},
+			expectedCmd: Command{Type: CommandTypeConfig, Config: ConfigOptions{Action: "show", Origin: true}},
+		},
+		{
+			name:        "origin without show",
+			args:        []string{"scratch-note", "config", "validate", "--origin"},
+			expectError: true,
+		},
+		{
+			name: "global flags before and after the command",
+			args: []string{"scratch-note", "--dir", "/tmp/notes", "migrate", "--set", "layout=daily", "--editor=nano"},
+			expectedCmd: Command{Type: CommandTypeMigrate, Overrides: []config.Override{
+				{Key: "scratch_note_dir", Value: "/tmp/notes", Flag: "--dir"},
+				{Key: "layout", Value: "daily", Flag: "--set layout"},
+				{Key: "editor", Value: "nano", Flag: "--editor"},
+			}},
+		},
+		{
+			name: "global flag with shorthand title",
+			args: []string{"scratch-note", "--dir", "/tmp/notes", "meeting"},
+			expectedCmd: Command{Type: CommandTypeCreate, Title: "meeting", Overrides: []config.Override{
+				{Key: "scratch_note_dir", Value: "/tmp/notes", Flag: "--dir"},
+			}},
+		},
+		{
+			name: "global flag before new flags",
+			args: []string{"scratch-note", "--notebook", "work", "-m", "hi", "--template=daily", "title"},
+			expectedCmd: Command{Type: CommandTypeCreate, Title: "title", Create: CreateOptions{Messages: []string{"hi"}, Template: "daily"}, Overrides: []config.Override{
+				{Key: "notebook", Value: "work", Flag: "--notebook"},
+			}},
+		},
+		{
+			name: "global flags before a command with flags",
+			args: []string{"scratch-note", "--notebook=work", "--set", "layout=daily", "trash", "empty", "--older-than", "1d"},
+			expectedCmd: Command{Type: CommandTypeTrash, Trash: TrashOptions{Action: TrashActionEmpty, OlderThan: 24 * time.Hour}, Overrides: []config.Override{
+				{Key: "notebook", Value: "work", Flag: "--notebook"},
+				{Key: "layout", Value: "daily", Flag: "--set layout"},
+			}},
+		},
+		{
+			name:        "global flag without value",
+			args:        []string{"scratch-note", "--notebook"},
+			expectError: true,
+		},
+		{
+			name: "notebook flag",
+			args: []string{"scratch-note", "notebooks", "--notebook", "work"},
+			expectedCmd: Command{Type: CommandTypeNotebooks, Overrides: []config.Override{
+				{Key: "notebook", Value: "work", Flag: "--notebook"},
+			}},
+		},
+		{
+			name:        "set without value",
+			args:        []string{"scratch-note", "list", "--set", "layout"},
+			expectError: true,
+		},
+		{
+			name:        "config unknown action",
+			args:        []string{"scratch-note", "config", "frobnicate"},
+			expectError: true,
+		},
+		{
+			name:        "help command",
+			args:        []string{"scratch-note", "help"},
+			expectedCmd: Command{Type: CommandTypeHelp},
+		},
+		{
+			name:        "help for command",
+			args:        []string{"scratch-note", "help", "new"},
+			expectedCmd: Command{Type: CommandTypeHelp, Topic: "new"},
+		},
+		{
+			name:        "help for unknown command",
+			args:        []string{"scratch-note", "help", "nope"},
+			expectError: true,
+		},
+		{
+			name:        "command help flag",
+			args:        []string{"scratch-note", "config", "--help"},
+			expectedCmd: Command{Type: CommandTypeHelp, Topic: "config"},
+		},
+		{
+			name:        "legacy help flag with topic",
+			args:        []string{"scratch-note", "-h", "config"},
+			expectedCmd: Command{Type: CommandTypeHelp, Topic: "config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseArgs(tt.args)
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("Unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(cmd, tt.expectedCmd) {
+				t.Errorf("ParseArgs(%v) = %+v, want %+v", tt.args, cmd, tt.expectedCmd)
+			}
+		})
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		name               string
+		args               []string
+		expectedPositional []string
+		expectedVerbose    bool
+	}{
+		{
+			name:               "flags before positional",
+			args:               []string{"-v", "a", "b"},
+			expectedPositional: []string{"a", "b"},
+			expectedVerbose:    true,
+		},
+		{
+			name:               "flags after positional",
+			args:               []string{"a", "-v", "b"},
+			expectedPositional: []string{"a", "b"},
+			expectedVerbose:    true,
+		},
+		{
+			name:               "terminator keeps flag-like positional",
+			args:               []string{"a", "--", "-v"},
+			expectedPositional: []string{"a", "-v"},
+			expectedVerbose:    false,
+		},
+		{
+			name:               "no arguments",
+			args:               []string{},
+			expectedPositional: nil,
+			expectedVerbose:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			verbose := fs.Bool("v", false, "verbose")
+
+			positional, err := parseFlags(fs, tt.args)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(positional, tt.expectedPositional) {
+				t.Errorf("positional = %q, want %q", positional, tt.expectedPositional)
+			}
+
+			if *verbose != tt.expectedVerbose {
+				t.Errorf("verbose = %v, want %v", *verbose, tt.expectedVerbose)
+			}
+		})
+	}
+}
+
+func TestWriteUsageListsEveryCommand(t *testing.T) {
+	var buf bytes.Buffer
+	writeUsage(&buf)
+	output := buf.String()
+
+	for _, group := range commandGroups {
+		if !strings.Contains(output, strings.ToUpper(group)+" COMMANDS:") {
+			t.Errorf("Usage should contain group %q:\n%s", group, output)
+		}
+	}
+
+	for _, sc := range subcommands() {
+		if !strings.Contains(output, sc.Usage) {
+			t.Errorf("Usage should mention command %q:\n%s", sc.Name, output)
+		}
+	}
+}
@@ -12,29 +12,59 @@ import (
 type Config struct {
 	ScratchNoteDir string `yaml:"scratch_note_dir"`
 	Editor         string `yaml:"editor"`
+	// KeepEmptyNotes keeps notes that are left empty instead of deleting them
+	KeepEmptyNotes bool `yaml:"keep_empty_notes"`
+	// DefaultTemplate names the template used when --template is not given
+	DefaultTemplate string `yaml:"default_template,omitempty"`
+	// Frontmatter writes YAML frontmatter with the note's metadata at creation
+	Frontmatter bool `yaml:"frontmatter"`
+	// FilenameTemplate lays out note filenames, e.g. "{date:2006-01-02}/{time:150405}-{slug}";
+	// empty keeps the default 2006-01-02_150405_title
+	FilenameTemplate string `yaml:"filename_template,omitempty"`
+	// FilenameExtension is the extension of new notes, e.g. ".txt" (default ".md")
+	FilenameExtension string `yaml:"filename_extension,omitempty"`
+	// FilenameSeparator replaces spaces in titles within filenames (default "-")
+	FilenameSeparator string `yaml:"filename_separator,omitempty"`
+	// Layout puts notes in date directories: flat (default), yearly, monthly or daily
+	Layout string `yaml:"layout,omitempty"`
+	// Notebook names the notebook to use when none is selected with
+	// --notebook or $SCRATCH_NOTE_NOTEBOOK; empty uses the settings above
+	Notebook string `yaml:"notebook,omitempty"`
+	// Notebooks are named sets of settings overriding the ones above
+	Notebooks map[string]Notebook `yaml:"notebooks,omitempty"`
 }
 
-// LoadConfig loads configuration from the specified file path
+// Notebook is a named notes directory with its own settings. Empty settings
+// are taken from the top level of the configuration.
+type Notebook struct {
+	ScratchNoteDir    string `yaml:"scratch_note_dir"`
+	Editor            string `yaml:"editor,omitempty"`
+	DefaultTemplate   string `yaml:"default_template,omitempty"`
+	FilenameTemplate  string `yaml:"filename_template,omitempty"`
+	FilenameExtension string `yaml:"filename_extension,omitempty"`
+	FilenameSeparator string `yaml:"filename_separator,omitempty"`
+	Layout            string `yaml:"layout,omitempty"`
+}
+
+// LoadConfig loads configuration from the specified file path. Unknown keys
+// and invalid values are rejected with a *ValidationError listing them all.
 func LoadConfig(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
+	src, problems, err := ParseConfig(configPath)
 	if err != nil {
 		return nil, err
 	}
-
-	var config Config
-	err = yaml.Unmarshal(data, &config)
-	if err != nil {
-		return nil, err
+	if len(problems) > 0 {
+		return nil, &ValidationError{Path: configPath, Problems: problems}
 	}
 
-	return &config, nil
+	return src.Config, nil
 }
 
-// GetDefaultConfig returns the default configuration
+// GetDefaultConfig returns the default configuration. The editor is left
+// empty so that $VISUAL, $EDITOR or vi is used when no layer sets one.
 func GetDefaultConfig() *Config {
 	return &Config{
 		ScratchNoteDir: "~/scratch-notes",
-		Editor:         "vi",
 	}
 }
 
@@ -52,19 +82,22 @@ func ExpandPath(path string) string {
 
 // CreateDefaultConfig creates a default configuration file
 func CreateDefaultConfig(configPath string) error {
-	config := GetDefaultConfig()
-	
+	return CreateConfig(configPath, GetDefaultConfig())
+}
+
+// CreateConfig writes config to configPath, creating its directory if needed
+func CreateConfig(configPath string, config *Config) error {
 	// Ensure config directory exists
 	configDir := filepath.Dir(configPath)
 	err := os.MkdirAll(configDir, 0755)
 	if err != nil {
 		return err
 	}
-	
+
 	data, err := yaml.Marshal(config)
 	if err != nil {
 		return err
 	}
-	
+
 	return os.WriteFile(configPath, data, 0644)
-}
\ No newline at end of file
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -41,6 +42,28 @@ editor: "nvim"`,
 				Editor:         "",
 			},
 		},
+		{
+			name: "config keeping empty notes",
+			configContent: `editor: "vim"
+keep_empty_notes: true`,
+			expectError: false,
+			expectedConfig: Config{
+				Editor:         "vim",
+				KeepEmptyNotes: true,
+			},
+		},
+		{
+			name: "config with filename format",
+			configContent: `filename_template: "{date:2006-01-02}/{time:150405}-{slug}"
+filename_extension: ".org"
+filename_separator: "_"`,
+			expectError: false,
+			expectedConfig: Config{
+				FilenameTemplate:  "{date:2006-01-02}/{time:150405}-{slug}",
+				FilenameExtension: ".org",
+				FilenameSeparator: "_",
+			},
+		},
 		{
 			name:          "invalid yaml",
 			configContent: `invalid: yaml: content: [`,
@@ -80,6 +103,18 @@ editor: "nvim"`,
 			if config.Editor != tt.expectedConfig.Editor {
 				t.Errorf("Editor = %q, want %q", config.Editor, tt.expectedConfig.Editor)
 			}
+
+			if config.KeepEmptyNotes != tt.expectedConfig.KeepEmptyNotes {
+				t.Errorf("KeepEmptyNotes = %v, want %v", config.KeepEmptyNotes, tt.expectedConfig.KeepEmptyNotes)
+			}
+
+			if config.FilenameTemplate != tt.expectedConfig.FilenameTemplate ||
+				config.FilenameExtension != tt.expectedConfig.FilenameExtension ||
+				config.FilenameSeparator != tt.expectedConfig.FilenameSeparator {
+				t.Errorf("Filename format = %q %q %q, want %q %q %q",
+					config.FilenameTemplate, config.FilenameExtension, config.FilenameSeparator,
+					tt.expectedConfig.FilenameTemplate, tt.expectedConfig.FilenameExtension, tt.expectedConfig.FilenameSeparator)
+			}
 		})
 	}
 }
@@ -97,8 +132,8 @@ func TestLoadConfigFileNotExists(t *testing.T) {
 func TestGetDefaultConfig(t *testing.T) {
 	config := GetDefaultConfig()
 	
-	if config.Editor != "vi" {
-		t.Errorf("Default editor should be 'vi', got: %q", config.Editor)
+	if config.Editor != "" {
+		t.Errorf("Default editor should be empty to fall back to $VISUAL and $EDITOR, got: %q", config.Editor)
 	}
 	
 	if config.ScratchNoteDir == "" {
@@ -172,4 +207,21 @@ func TestCreateDefaultConfig(t *testing.T) {
 	if config.ScratchNoteDir != defaultConfig.ScratchNoteDir {
 		t.Errorf("Created config directory = %q, want %q", config.ScratchNoteDir, defaultConfig.ScratchNoteDir)
 	}
-}
\ No newline at end of file
+}
+func TestCreateConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "nested", "config.yaml")
+	want := &Config{ScratchNoteDir: "/srv/notes", Editor: "nano", Layout: "monthly",
+		Notebooks: map[string]Notebook{"work": {ScratchNoteDir: "/srv/work", Layout: "daily"}}}
+
+	if err := CreateConfig(configPath, want); err != nil {
+		t.Fatalf("Failed to create config: %v", err)
+	}
+
+	got, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load created config: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadConfig() = %+v, want %+v", got, want)
+	}
+}
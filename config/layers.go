@@ -0,0 +1,248 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SystemConfigPath is the configuration file shared by all users
+const SystemConfigPath = "/etc/scratch-note/config.yaml"
+
+// ProjectConfigName is the configuration file FindProjectConfig looks for
+const ProjectConfigName = ".scratch-note.yaml"
+
+// EnvPrefix starts the environment variables that set configuration keys
+const EnvPrefix = "SCRATCH_NOTE_"
+
+// Layers of the configuration, in the order LoadLayers applies them
+const (
+	LayerDefault = "default"
+	LayerSystem  = "system"
+	LayerUser    = "user"
+	LayerProject = "project"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+)
+
+// Origin tells where a configuration value came from
+type Origin struct {
+	// Layer is one of the Layer constants, empty for a single parsed file
+	Layer string
+	// Path is the file, "$VARIABLE" or "--flag" that set the value
+	Path string
+	// Line and Column locate the value in a file
+	Line, Column int
+}
+
+func (o Origin) String() string {
+	if o.Layer == LayerDefault {
+		return LayerDefault
+	}
+	location := o.Path
+	if o.Line > 0 {
+		location += fmt.Sprintf(":%d", o.Line)
+	}
+	if o.Layer == "" {
+		return location
+	}
+	return o.Layer + " " + location
+}
+
+// Override sets a configuration key from the command line
+type Override struct {
+	Key   string
+	Value string
+	// Flag is the flag the value was given with, e.g. "--editor"
+	Flag string
+}
+
+// Layers tells LoadLayers where to look for configuration. Files that do
+// not exist, and empty paths, are skipped.
+type Layers struct {
+	SystemPath  string
+	UserPath    string
+	ProjectPath string
+	// Environ holds the environment as "KEY=value" strings, see os.Environ
+	Environ   []string
+	Overrides []Override
+}
+
+// EnvName returns the environment variable that sets key: SCRATCH_NOTE_
+// followed by the key in upper case, e.g. SCRATCH_NOTE_EDITOR, without
+// repeating the prefix (SCRATCH_NOTE_DIR for scratch_note_dir)
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.TrimPrefix(key, "scratch_note_"))
+}
+
+// FindProjectConfig returns the ProjectConfigName file in dir or the
+// nearest of its parents, or "" if there is none
+func FindProjectConfig(dir string) string {
+	for {
+		path := filepath.Join(dir, ProjectConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadLayers merges the configuration layers, each overriding the ones
+// before it: the defaults of GetDefaultConfig, with $VISUAL or $EDITOR as
+// the editor, then the system, user and project files, SCRATCH_NOTE_*
+// environment variables and finally overrides from flags. The settings of
+// the notebook named by the notebook key are then applied, see Select.
+// Relative directories in files are relative to the file. Problems in any
+// layer are returned together; the error is only set when a file cannot be
+// read.
+func LoadLayers(layers Layers) (*Source, []Problem, error) {
+	merged := &Source{Config: GetDefaultConfig(), origins: map[string]Origin{}}
+	for _, key := range Keys() {
+		merged.origins[key] = Origin{Layer: LayerDefault}
+	}
+
+	env := map[string]string{}
+	for _, kv := range layers.Environ {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			env[name] = value
+		}
+	}
+	for _, name := range []string{"EDITOR", "VISUAL"} {
+		if strings.TrimSpace(env[name]) != "" {
+			merged.Config.Editor = env[name]
+			merged.origins["editor"] = Origin{Layer: LayerEnv, Path: "$" + name}
+		}
+	}
+
+	var problems []Problem
+	files := []struct{ layer, path string }{
+		{LayerSystem, layers.SystemPath},
+		{LayerUser, layers.UserPath},
+		{LayerProject, layers.ProjectPath},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		src, fileProblems, err := parseConfigFile(file.path, file.layer)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file: %v", err)
+		}
+		problems = append(problems, fileProblems...)
+		merged.merge(src)
+	}
+
+	for _, key := range Keys() {
+		name := EnvName(key)
+		if value, ok := env[name]; ok {
+			origin := Origin{Layer: LayerEnv, Path: "$" + name}
+			if err := merged.set(key, value, origin); err != nil {
+				problems = append(problems, Problem{Path: origin.Path, Key: key, Message: err.Error()})
+			}
+		}
+	}
+
+	for _, override := range layers.Overrides {
+		origin := Origin{Layer: LayerFlag, Path: override.Flag}
+		if err := merged.set(override.Key, override.Value, origin); err != nil {
+			problems = append(problems, Problem{Path: origin.Path, Key: override.Key, Message: err.Error()})
+		}
+	}
+
+	selected, err := merged.Select(merged.Config.Notebook)
+	if err != nil {
+		return merged, append(problems, merged.Problem("notebook", "notebook: %v", err)), nil
+	}
+	return selected, problems, nil
+}
+
+// merge copies the keys set in src over s. A notebook defined in src
+// replaces the notebook of the same name as a whole.
+func (s *Source) merge(src *Source) {
+	for name := range src.Config.Notebooks {
+		for key := range s.origins {
+			if strings.HasPrefix(key, notebookKey(name, "")) {
+				delete(s.origins, key)
+			}
+		}
+	}
+
+	fields := configFields()
+	to, from := reflect.ValueOf(s.Config).Elem(), reflect.ValueOf(src.Config).Elem()
+	for key, origin := range src.origins {
+		s.origins[key] = origin
+		if index, ok := fields[key]; ok && key != "notebooks" {
+			to.Field(index).Set(from.Field(index))
+		}
+	}
+
+	if _, ok := src.origins["scratch_note_dir"]; ok {
+		s.Config.ScratchNoteDir = resolveDir(s.Config.ScratchNoteDir, src.Path)
+	}
+	if len(src.Config.Notebooks) > 0 && s.Config.Notebooks == nil {
+		s.Config.Notebooks = map[string]Notebook{}
+	}
+	for name, notebook := range src.Config.Notebooks {
+		notebook.ScratchNoteDir = resolveDir(notebook.ScratchNoteDir, src.Path)
+		s.Config.Notebooks[name] = notebook
+	}
+}
+
+// resolveDir returns dir relative to the directory of the config file at
+// configPath, unless it is absolute or starts with ~/
+func resolveDir(dir, configPath string) string {
+	if dir == "" || filepath.IsAbs(dir) || strings.HasPrefix(dir, "~/") {
+		return dir
+	}
+	return filepath.Join(filepath.Dir(configPath), dir)
+}
+
+// set parses value as the value of key, which must be a known key
+func (s *Source) set(key, value string, origin Origin) error {
+	index, ok := configFields()[key]
+	if !ok {
+		message := fmt.Sprintf("unknown key %q", key)
+		if suggestion := suggestKey(key, configFields()); suggestion != "" {
+			message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+		}
+		return fmt.Errorf("%s", message)
+	}
+
+	field := reflect.ValueOf(s.Config).Elem().Field(index)
+	switch field.Kind() {
+	case reflect.Map:
+		return fmt.Errorf("%s can only be set in a config file", key)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: invalid boolean %q", key, value)
+		}
+		field.SetBool(b)
+	default:
+		field.SetString(value)
+	}
+	s.origins[key] = origin
+	return nil
+}
+
+// Value returns the value of key formatted for display
+func (s *Source) Value(key string) string {
+	index, ok := configFields()[key]
+	if !ok {
+		return ""
+	}
+	if key == "notebooks" {
+		return strings.Join(s.Config.NotebookNames(), ", ")
+	}
+	return fmt.Sprint(reflect.ValueOf(s.Config).Elem().Field(index).Interface())
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadLayers(t *testing.T) {
+	dir := t.TempDir()
+	systemPath := filepath.Join(dir, "etc", "config.yaml")
+	userPath := filepath.Join(dir, "home", "config.yaml")
+	projectDir := filepath.Join(dir, "project")
+	for path, content := range map[string]string{
+		systemPath: "scratch_note_dir: /srv/notes\nlayout: yearly\nfrontmatter: true\n",
+		userPath:   "editor: nano\nlayout: monthly\n",
+		filepath.Join(projectDir, ProjectConfigName): "scratch_note_dir: notes\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write config: %v", err)
+		}
+	}
+
+	src, problems, err := LoadLayers(Layers{
+		SystemPath:  systemPath,
+		UserPath:    userPath,
+		ProjectPath: FindProjectConfig(filepath.Join(projectDir, "sub", "dir")),
+		Environ:     []string{"VISUAL=code", "SCRATCH_NOTE_LAYOUT=daily", "SCRATCH_NOTE_KEEP_EMPTY_NOTES=true"},
+		Overrides:   []Override{{Key: "editor", Value: "vim", Flag: "--editor"}},
+	})
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("LoadLayers() unexpected problems: %v %v", problems, err)
+	}
+
+	tests := []struct {
+		key    string
+		value  string
+		origin string
+	}{
+		{"scratch_note_dir", filepath.Join(projectDir, "notes"), "project " + filepath.Join(projectDir, ProjectConfigName) + ":1"},
+		{"editor", "vim", "flag --editor"},
+		{"keep_empty_notes", "true", "env $SCRATCH_NOTE_KEEP_EMPTY_NOTES"},
+		{"frontmatter", "true", "system " + systemPath + ":3"},
+		{"layout", "daily", "env $SCRATCH_NOTE_LAYOUT"},
+		{"filename_template", "", "default"},
+	}
+	for _, tt := range tests {
+		if got := src.Value(tt.key); got != tt.value {
+			t.Errorf("Value(%s) = %q, want %q", tt.key, got, tt.value)
+		}
+		if got := src.Origin(tt.key).String(); got != tt.origin {
+			t.Errorf("Origin(%s) = %q, want %q", tt.key, got, tt.origin)
+		}
+	}
+}
+
+func TestLoadLayersEditorFromEnvironment(t *testing.T) {
+	src, _, err := LoadLayers(Layers{Environ: []string{"EDITOR=nano", "VISUAL=code --wait"}})
+	if err != nil {
+		t.Fatalf("LoadLayers() unexpected error: %v", err)
+	}
+	if src.Config.Editor != "code --wait" || src.Origin("editor").String() != "env $VISUAL" {
+		t.Errorf("Editor = %q from %s, want $VISUAL", src.Config.Editor, src.Origin("editor"))
+	}
+	if src.Has("scratch_note_dir") || src.Config.ScratchNoteDir != "~/scratch-notes" {
+		t.Errorf("ScratchNoteDir = %q, want the default", src.Config.ScratchNoteDir)
+	}
+}
+
+func TestLoadLayersProblems(t *testing.T) {
+	userPath := writeConfig(t, "editr: vim\n")
+	_, problems, err := LoadLayers(Layers{
+		UserPath:  userPath,
+		Environ:   []string{"SCRATCH_NOTE_FRONTMATTER=maybe"},
+		Overrides: []Override{{Key: "layot", Value: "daily", Flag: "--set layot"}},
+	})
+	if err != nil {
+		t.Fatalf("LoadLayers() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		userPath + `:1:1: unknown key "editr" (did you mean "editor"?)`,
+		`$SCRATCH_NOTE_FRONTMATTER: frontmatter: invalid boolean "maybe"`,
+		`--set layot: unknown key "layot" (did you mean "layout"?)`,
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("LoadLayers() problems =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	tests := map[string]string{
+		"scratch_note_dir":  "SCRATCH_NOTE_DIR",
+		"editor":            "SCRATCH_NOTE_EDITOR",
+		"filename_template": "SCRATCH_NOTE_FILENAME_TEMPLATE",
+	}
+	for key, expected := range tests {
+		if got := EnvName(key); got != expected {
+			t.Errorf("EnvName(%q) = %q, want %q", key, got, expected)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// notebookKey returns the key under which the origin of a notebook setting
+// is recorded, e.g. "notebooks.work.editor"
+func notebookKey(name, key string) string {
+	return "notebooks." + name + "." + key
+}
+
+// decodeNotebooks decodes the notebooks mapping node into s, recording the
+// position of each notebook setting as set by layer
+func (s *Source) decodeNotebooks(node *yaml.Node, layer string) []Problem {
+	if node.Kind != yaml.MappingNode {
+		return []Problem{{Line: node.Line, Column: node.Column, Key: "notebooks", Message: "notebooks must be a mapping of names to settings"}}
+	}
+
+	fields := yamlFields(reflect.TypeOf(Notebook{}))
+	var problems []Problem
+	s.Config.Notebooks = map[string]Notebook{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		nameNode, settings := node.Content[i], node.Content[i+1]
+		name := nameNode.Value
+		if _, seen := s.Config.Notebooks[name]; seen {
+			problems = append(problems, Problem{Line: nameNode.Line, Column: nameNode.Column, Key: "notebooks",
+				Message: fmt.Sprintf("duplicate notebook %q", name)})
+			continue
+		}
+		if strings.TrimSpace(name) == "" || strings.ContainsAny(name, ". \t") {
+			problems = append(problems, Problem{Line: nameNode.Line, Column: nameNode.Column, Key: "notebooks",
+				Message: fmt.Sprintf("invalid notebook name %q", name)})
+			continue
+		}
+		if settings.Kind != yaml.MappingNode {
+			problems = append(problems, Problem{Line: settings.Line, Column: settings.Column, Key: "notebooks",
+				Message: fmt.Sprintf("notebook %q must be a mapping of keys to values", name)})
+			continue
+		}
+
+		var notebook Notebook
+		for j := 0; j+1 < len(settings.Content); j += 2 {
+			keyNode, valueNode := settings.Content[j], settings.Content[j+1]
+			key := keyNode.Value
+
+			index, known := fields[key]
+			first, seen := s.origins[notebookKey(name, key)]
+			switch {
+			case !known:
+				message := fmt.Sprintf("unknown notebook key %q", key)
+				if suggestion := suggestKey(key, fields); suggestion != "" {
+					message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+				}
+				problems = append(problems, Problem{Line: keyNode.Line, Column: keyNode.Column, Key: key, Message: message})
+				continue
+			case seen:
+				problems = append(problems, Problem{Line: keyNode.Line, Column: keyNode.Column, Key: key,
+					Message: fmt.Sprintf("duplicate key %q (first set on line %d)", key, first.Line)})
+				continue
+			}
+
+			s.origins[notebookKey(name, key)] = Origin{Layer: layer, Path: s.Path, Line: valueNode.Line, Column: valueNode.Column}
+			field := reflect.ValueOf(&notebook).Elem().Field(index)
+			if err := valueNode.Decode(field.Addr().Interface()); err != nil {
+				p := yamlProblem(err, key)
+				p.Line, p.Column = valueNode.Line, valueNode.Column
+				problems = append(problems, p)
+			}
+		}
+
+		if strings.TrimSpace(notebook.ScratchNoteDir) == "" {
+			problems = append(problems, Problem{Line: nameNode.Line, Column: nameNode.Column, Key: "notebooks",
+				Message: fmt.Sprintf("notebook %q: scratch_note_dir is not set", name)})
+		}
+		s.Config.Notebooks[name] = notebook
+	}
+	return problems
+}
+
+// NotebookNames returns the names of the configured notebooks in order
+func (c *Config) NotebookNames() []string {
+	names := make([]string, 0, len(c.Notebooks))
+	for name := range c.Notebooks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select returns the configuration with the settings of the named notebook
+// applied over the top level ones, an empty name selecting none. Settings
+// given with SCRATCH_NOTE_* variables or flags keep their value.
+func (s *Source) Select(name string) (*Source, error) {
+	base := s
+	if s.unselected != nil {
+		base = s.unselected
+	}
+
+	copied := *base.Config
+	selected := &Source{Path: base.Path, Config: &copied, origins: map[string]Origin{}, unselected: base}
+	for key, origin := range base.origins {
+		selected.origins[key] = origin
+	}
+	if name == "" {
+		selected.Config.Notebook = ""
+		return selected, nil
+	}
+
+	notebook, ok := base.Config.Notebooks[name]
+	if !ok {
+		if len(base.Config.Notebooks) == 0 {
+			return nil, fmt.Errorf("unknown notebook %q (no notebooks are configured)", name)
+		}
+		return nil, fmt.Errorf("unknown notebook %q (known: %s)", name, strings.Join(base.Config.NotebookNames(), ", "))
+	}
+	selected.Config.Notebook = name
+
+	fields := configFields()
+	to, from := reflect.ValueOf(selected.Config).Elem(), reflect.ValueOf(notebook)
+	for i, key := range yamlKeys(from.Type()) {
+		value := from.Field(i).String()
+		if value == "" {
+			continue
+		}
+		origin := selected.origins[key]
+		if origin.Layer == LayerFlag || origin.Layer == LayerEnv && origin.Path == "$"+EnvName(key) {
+			continue
+		}
+		to.Field(fields[key]).SetString(value)
+		selected.origins[key] = base.origins[notebookKey(name, key)]
+	}
+	return selected, nil
+}
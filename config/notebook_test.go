@@ -0,0 +1,155 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const notebooksConfig = `scratch_note_dir: ~/notes
+editor: vim
+layout: monthly
+notebook: work
+notebooks:
+  work:
+    scratch_note_dir: work-notes
+    editor: code --wait
+  client-a:
+    scratch_note_dir: /srv/client-a
+    filename_template: "{date:2006-01-02}_{slug}"
+    default_template: meeting
+`
+
+func TestLoadLayersNotebooks(t *testing.T) {
+	userPath := writeConfig(t, notebooksConfig)
+	configDir := filepath.Dir(userPath)
+
+	tests := []struct {
+		name      string
+		layers    Layers
+		notebook  string
+		dir       string
+		editor    string
+		template  string
+		dirOrigin string
+	}{
+		{
+			name:      "default notebook from the config",
+			layers:    Layers{UserPath: userPath},
+			notebook:  "work",
+			dir:       filepath.Join(configDir, "work-notes"),
+			editor:    "code --wait",
+			dirOrigin: "user " + userPath + ":7",
+		},
+		{
+			name:      "notebook from the environment",
+			layers:    Layers{UserPath: userPath, Environ: []string{"SCRATCH_NOTE_NOTEBOOK=client-a"}},
+			notebook:  "client-a",
+			dir:       "/srv/client-a",
+			editor:    "vim",
+			template:  "{date:2006-01-02}_{slug}",
+			dirOrigin: "user " + userPath + ":10",
+		},
+		{
+			name: "notebook from a flag with the directory overridden",
+			layers: Layers{UserPath: userPath, Environ: []string{"SCRATCH_NOTE_NOTEBOOK=client-a"}, Overrides: []Override{
+				{Key: "notebook", Value: "work", Flag: "--notebook"},
+				{Key: "scratch_note_dir", Value: "/tmp/notes", Flag: "--dir"},
+			}},
+			notebook:  "work",
+			dir:       "/tmp/notes",
+			editor:    "code --wait",
+			dirOrigin: "flag --dir",
+		},
+		{
+			name:      "no notebook",
+			layers:    Layers{UserPath: userPath, Overrides: []Override{{Key: "notebook", Value: "", Flag: "--notebook"}}},
+			dir:       "~/notes",
+			editor:    "vim",
+			dirOrigin: "user " + userPath + ":1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, problems, err := LoadLayers(tt.layers)
+			if err != nil || len(problems) > 0 {
+				t.Fatalf("LoadLayers() unexpected problems: %v %v", problems, err)
+			}
+			cfg := src.Config
+			if cfg.Notebook != tt.notebook || cfg.ScratchNoteDir != tt.dir || cfg.Editor != tt.editor || cfg.FilenameTemplate != tt.template {
+				t.Errorf("LoadLayers() = notebook %q dir %q editor %q template %q, want %q %q %q %q",
+					cfg.Notebook, cfg.ScratchNoteDir, cfg.Editor, cfg.FilenameTemplate, tt.notebook, tt.dir, tt.editor, tt.template)
+			}
+			if cfg.Layout != "monthly" {
+				t.Errorf("Layout = %q, want the top-level monthly", cfg.Layout)
+			}
+			if got := src.Origin("scratch_note_dir").String(); got != tt.dirOrigin {
+				t.Errorf("Origin(scratch_note_dir) = %q, want %q", got, tt.dirOrigin)
+			}
+		})
+	}
+}
+
+func TestLoadLayersMergesNotebooks(t *testing.T) {
+	systemPath := writeConfig(t, "notebooks:\n  shared:\n    scratch_note_dir: /srv/shared\n  work:\n    scratch_note_dir: /srv/work\n    layout: daily\n")
+	userPath := writeConfig(t, "notebooks:\n  work:\n    scratch_note_dir: /home/me/work\n")
+
+	src, problems, err := LoadLayers(Layers{SystemPath: systemPath, UserPath: userPath})
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("LoadLayers() unexpected problems: %v %v", problems, err)
+	}
+	if got := strings.Join(src.Config.NotebookNames(), ","); got != "shared,work" {
+		t.Errorf("NotebookNames() = %q, want shared,work", got)
+	}
+
+	// A notebook is replaced as a whole by a later layer
+	work, err := src.Select("work")
+	if err != nil {
+		t.Fatalf("Select(work) unexpected error: %v", err)
+	}
+	if work.Config.ScratchNoteDir != "/home/me/work" || work.Config.Layout != "" {
+		t.Errorf("Select(work) = %+v, want the user's notebook only", work.Config)
+	}
+
+	// Selecting again starts from the top level, not the previous notebook
+	shared, err := work.Select("shared")
+	if err != nil || shared.Config.ScratchNoteDir != "/srv/shared" {
+		t.Errorf("Select(shared) = %+v, %v", shared, err)
+	}
+}
+
+func TestLoadLayersNotebookProblems(t *testing.T) {
+	userPath := writeConfig(t, `notebooks:
+  work:
+    scratch_note_dir: /srv/work
+    layot: daily
+  empty:
+    editor: nano
+  bad name: {}
+`)
+
+	_, problems, err := LoadLayers(Layers{UserPath: userPath, Overrides: []Override{{Key: "notebook", Value: "wrok", Flag: "--notebook"}}})
+	if err != nil {
+		t.Fatalf("LoadLayers() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		userPath + `:4:5: unknown notebook key "layot" (did you mean "layout"?)`,
+		userPath + `:5:3: notebook "empty": scratch_note_dir is not set`,
+		userPath + `:7:3: invalid notebook name "bad name"`,
+		`--notebook: notebook: unknown notebook "wrok" (known: empty, work)`,
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("LoadLayers() problems =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+
+	_, problems, _ = LoadLayers(Layers{Environ: []string{"SCRATCH_NOTE_NOTEBOOKS=work"}})
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "only be set in a config file") {
+		t.Errorf("LoadLayers() problems = %v, want notebooks rejected from the environment", problems)
+	}
+}
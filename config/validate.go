@@ -0,0 +1,259 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is an issue found in the configuration. Path is the file,
+// environment variable or flag the problem is in; Line and Column are
+// 1-based and zero when the problem is not tied to a position.
+type Problem struct {
+	Path    string
+	Line    int
+	Column  int
+	Key     string
+	Message string
+}
+
+func (p Problem) String() string {
+	location := p.Path
+	if p.Line > 0 {
+		location += fmt.Sprintf(":%d", p.Line)
+		if p.Column > 0 {
+			location += fmt.Sprintf(":%d", p.Column)
+		}
+	}
+	if location == "" {
+		return p.Message
+	}
+	return location + ": " + p.Message
+}
+
+// ValidationError lists every problem found in a config file
+type ValidationError struct {
+	Path     string
+	Problems []Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		if p.Path == "" {
+			p.Path = e.Path
+		}
+		lines[i] = p.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Source is a configuration with the origin of each key it sets: a single
+// file for ParseConfig, every layer for LoadLayers
+type Source struct {
+	Path   string
+	Config *Config
+
+	origins map[string]Origin
+	// unselected is the configuration before a notebook was selected
+	unselected *Source
+}
+
+// Problem returns a problem with the value of key, positioned where the
+// value was set
+func (s *Source) Problem(key, format string, args ...any) Problem {
+	p := Problem{Key: key, Message: fmt.Sprintf(format, args...)}
+	if origin, ok := s.origins[key]; ok && origin.Layer != LayerDefault {
+		p.Path, p.Line, p.Column = origin.Path, origin.Line, origin.Column
+	}
+	return p
+}
+
+// Has reports whether key is set, rather than left at its default
+func (s *Source) Has(key string) bool {
+	origin, ok := s.origins[key]
+	return ok && origin.Layer != LayerDefault
+}
+
+// Origin returns where the value of key came from
+func (s *Source) Origin(key string) Origin {
+	if origin, ok := s.origins[key]; ok {
+		return origin
+	}
+	return Origin{Layer: LayerDefault}
+}
+
+// yamlLinePattern finds the line in yaml.v3 error messages
+var yamlLinePattern = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// ParseConfig reads the config file at configPath. Unknown or repeated keys
+// and values of the wrong type are returned as problems, all of them at once,
+// along with the configuration decoded from the remaining keys. The error is
+// only set when the file cannot be read.
+func ParseConfig(configPath string) (*Source, []Problem, error) {
+	return parseConfigFile(configPath, "")
+}
+
+// parseConfigFile is ParseConfig for the file of the given layer
+func parseConfigFile(configPath, layer string) (*Source, []Problem, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	src := &Source{Path: configPath, Config: &Config{}, origins: map[string]Origin{}}
+	problems := src.decode(data, layer)
+	for i := range problems {
+		problems[i].Path = configPath
+	}
+	return src, problems, nil
+}
+
+// decode decodes the YAML document data into s, recording the position of
+// each key as set by layer
+func (s *Source) decode(data []byte, layer string) []Problem {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []Problem{yamlProblem(err, "")}
+	}
+	if len(doc.Content) == 0 {
+		// An empty file sets nothing
+		return nil
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return []Problem{{Line: root.Line, Column: root.Column, Message: "config must be a mapping of keys to values"}}
+	}
+
+	fields := configFields()
+	var problems []Problem
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], root.Content[i+1]
+		key := keyNode.Value
+
+		index, known := fields[key]
+		first, seen := s.origins[key]
+		switch {
+		case !known:
+			message := fmt.Sprintf("unknown key %q", key)
+			if suggestion := suggestKey(key, fields); suggestion != "" {
+				message += fmt.Sprintf(" (did you mean %q?)", suggestion)
+			}
+			problems = append(problems, Problem{Line: keyNode.Line, Column: keyNode.Column, Key: key, Message: message})
+			continue
+		case seen:
+			problems = append(problems, Problem{Line: keyNode.Line, Column: keyNode.Column, Key: key,
+				Message: fmt.Sprintf("duplicate key %q (first set on line %d)", key, first.Line)})
+			continue
+		}
+
+		s.origins[key] = Origin{Layer: layer, Path: s.Path, Line: valueNode.Line, Column: valueNode.Column}
+		if key == "notebooks" {
+			problems = append(problems, s.decodeNotebooks(valueNode, layer)...)
+			continue
+		}
+		field := reflect.ValueOf(s.Config).Elem().Field(index)
+		if err := valueNode.Decode(field.Addr().Interface()); err != nil {
+			p := yamlProblem(err, key)
+			p.Line, p.Column = valueNode.Line, valueNode.Column
+			problems = append(problems, p)
+		}
+	}
+
+	return problems
+}
+
+// configFields maps the yaml keys of Config to their field index
+func configFields() map[string]int {
+	return yamlFields(reflect.TypeOf(Config{}))
+}
+
+// yamlFields maps the yaml keys of the struct type t to their field index
+func yamlFields(t reflect.Type) map[string]int {
+	fields := map[string]int{}
+	for i, key := range yamlKeys(t) {
+		fields[key] = i
+	}
+	return fields
+}
+
+// Keys returns the yaml keys of Config in the order of its fields
+func Keys() []string {
+	return yamlKeys(reflect.TypeOf(Config{}))
+}
+
+// yamlKeys returns the yaml keys of the struct type t in field order
+func yamlKeys(t reflect.Type) []string {
+	keys := make([]string, t.NumField())
+	for i := range keys {
+		keys[i], _, _ = strings.Cut(t.Field(i).Tag.Get("yaml"), ",")
+	}
+	return keys
+}
+
+// suggestKey returns the known key closest to an unknown one, if any is close
+func suggestKey(key string, fields map[string]int) string {
+	known := make([]string, 0, len(fields))
+	for name := range fields {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+
+	best, bestDistance := "", 3
+	normalized := strings.ToLower(strings.ReplaceAll(key, "-", "_"))
+	for _, name := range known {
+		if d := editDistance(normalized, name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// yamlProblem turns a yaml.v3 error into a problem, taking the line from
+// the message when it has one
+func yamlProblem(err error, key string) Problem {
+	message := err.Error()
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) && len(typeErr.Errors) > 0 {
+		message = typeErr.Errors[0]
+	}
+
+	p := Problem{Key: key, Message: message}
+	if m := yamlLinePattern.FindStringSubmatch(message); m != nil {
+		p.Line, _ = strconv.Atoi(m[1])
+		p.Message = m[2]
+	}
+	if key != "" {
+		p.Message = fmt.Sprintf("%s: %s", key, p.Message)
+	}
+	return p
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes content to a config file in a temporary directory
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config file: %v", err)
+	}
+	return configPath
+}
+
+func TestParseConfigProblems(t *testing.T) {
+	configPath := writeConfig(t, `scratch-note_dir: "~/notes"
+editor: nvim
+keep_empty_notes: sometimes
+editor: vim
+frontmater: true
+`)
+
+	src, problems, err := ParseConfig(configPath)
+	if err != nil {
+		t.Fatalf("ParseConfig() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		`1:1: unknown key "scratch-note_dir" (did you mean "scratch_note_dir"?)`,
+		"3:19: keep_empty_notes: cannot unmarshal !!str `sometimes` into bool",
+		`4:1: duplicate key "editor" (first set on line 2)`,
+		`5:1: unknown key "frontmater" (did you mean "frontmatter"?)`,
+	}
+	for i := range expected {
+		expected[i] = configPath + ":" + expected[i]
+	}
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.String())
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("ParseConfig() problems =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+
+	// Valid keys are still decoded
+	if src.Config.Editor != "nvim" {
+		t.Errorf("Editor = %q, want nvim", src.Config.Editor)
+	}
+	if p := src.Problem("editor", "not found"); p.Line != 2 || p.Column != 9 {
+		t.Errorf("Problem(editor) = %+v, want line 2 column 9", p)
+	}
+	if p := src.Problem("layout", "bad"); p.Line != 0 {
+		t.Errorf("Problem(layout) for an unset key should have no position, got %+v", p)
+	}
+}
+
+func TestParseConfigSyntaxError(t *testing.T) {
+	_, problems, err := ParseConfig(writeConfig(t, "editor: vim\n  bad: [\n"))
+	if err != nil {
+		t.Fatalf("ParseConfig() unexpected error: %v", err)
+	}
+	if len(problems) != 1 || problems[0].Line == 0 {
+		t.Errorf("ParseConfig() problems = %+v, want one positioned syntax error", problems)
+	}
+
+	_, problems, _ = ParseConfig(writeConfig(t, "- editor\n"))
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "mapping") {
+		t.Errorf("ParseConfig() problems = %+v, want a mapping error", problems)
+	}
+}
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	configPath := writeConfig(t, "scratch-note_dir: ~/notes\neditr: vim\n")
+
+	_, err := LoadConfig(configPath)
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("LoadConfig() error = %v, want *ValidationError", err)
+	}
+	if len(validationErr.Problems) != 2 {
+		t.Errorf("Problems = %+v, want 2", validationErr.Problems)
+	}
+	if !strings.HasPrefix(err.Error(), configPath+":1:1: unknown key") {
+		t.Errorf("Error() = %q", err.Error())
+	}
+}
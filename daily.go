@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"scratch-note/utils"
+)
+
+// dailyHeadingLayout formats the heading added for each daily entry
+const dailyHeadingLayout = "15:04"
+
+// DailyOptions holds the options of the daily command
+type DailyOptions struct {
+	// Date selects the day of the note; zero means today
+	Date time.Time
+	// Yesterday selects the day before today
+	Yesterday bool
+}
+
+// entryTime returns the time of a new entry: the selected day at the
+// current time of day
+func (o DailyOptions) entryTime(now time.Time) time.Time {
+	switch {
+	case o.Yesterday:
+		return now.AddDate(0, 0, -1)
+	case !o.Date.IsZero():
+		y, m, d := o.Date.In(now.Location()).Date()
+		return time.Date(y, m, d, now.Hour(), now.Minute(), now.Second(), 0, now.Location())
+	}
+	return now
+}
+
+// OpenDailyNote adds an entry heading for t to the daily note of t's day in
+// directory, creating the note if needed, and opens it in editor with the
+// cursor below the heading when the editor supports it. If discardEmpty is
+// set and nothing is written under the heading, the entry is removed again
+// (along with a note created for it) and the returned error is ErrNoteDiscarded.
+func OpenDailyNote(directory string, t time.Time, editor EditorLauncher, discardEmpty bool) (string, error) {
+	if _, err := os.Stat(directory); os.IsNotExist(err) {
+		return "", fmt.Errorf("scratch-note directory does not exist: %s", directory)
+	}
+
+	filePath := filepath.Join(directory, noteNames.DailyFileName(t))
+	original, err := os.ReadFile(filePath)
+	created := os.IsNotExist(err)
+	if err != nil && !created {
+		return "", fmt.Errorf("failed to read daily note: %v", err)
+	}
+
+	// appendToFile ends an unterminated last line before the entry, which a
+	// discarded entry takes back too
+	size := len(original)
+	if len(original) > 0 && !bytes.HasSuffix(original, []byte("\n")) {
+		original = append(original, '\n')
+	}
+	entry := dailyEntry(original, t)
+	if created {
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			return "", fmt.Errorf("failed to create directory: %v", err)
+		}
+	}
+	if err := appendToFile(filePath, entry, os.O_CREATE); err != nil {
+		return "", err
+	}
+
+	// The entry ends with an empty line for the user to write on
+	line := bytes.Count(original, []byte("\n")) + bytes.Count(entry, []byte("\n"))
+	if launcher, ok := editor.(LineLauncher); ok {
+		err = launcher.LaunchAt(filePath, line)
+	} else {
+		err = editor.Launch(filePath)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if discardEmpty {
+		discarded, err := discardDailyEntry(filePath, append(original, entry...), size, created)
+		if err != nil {
+			return "", err
+		}
+		if discarded {
+			return filePath, ErrNoteDiscarded
+		}
+	}
+
+	return filePath, nil
+}
+
+// dailyEntry returns the text appended to a daily note holding content for
+// a new entry at t. A new note starts with the date as its title.
+func dailyEntry(content []byte, t time.Time) []byte {
+	var b bytes.Buffer
+	if len(content) == 0 {
+		fmt.Fprintf(&b, "# %s\n", t.Format(utils.DailyLayout))
+	}
+	fmt.Fprintf(&b, "\n## %s\n\n", t.Format(dailyHeadingLayout))
+	return b.Bytes()
+}
+
+// discardDailyEntry undoes an entry left empty: the note is truncated back
+// to originalSize, or removed if it was created for the entry
+func discardDailyEntry(filePath string, withEntry []byte, originalSize int, created bool) (bool, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read note: %v", err)
+	}
+	if !bytes.Equal(bytes.TrimSpace(content), bytes.TrimSpace(withEntry)) {
+		return false, nil
+	}
+
+	if created {
+		err = os.Remove(filePath)
+	} else {
+		err = os.Truncate(filePath, int64(originalSize))
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to discard empty entry: %v", err)
+	}
+	return true, nil
+}
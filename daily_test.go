@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// lineEditor is a LineLauncher recording the line it was asked to open at
+type lineEditor struct {
+	MockEditor
+	Lines []int
+}
+
+func (l *lineEditor) LaunchAt(filePath string, line int) error {
+	l.Lines = append(l.Lines, line)
+	return l.Launch(filePath)
+}
+
+func TestOpenDailyNote(t *testing.T) {
+	dir := t.TempDir()
+	morning := time.Date(2025, 8, 16, 9, 5, 0, 0, time.Local)
+	afternoon := time.Date(2025, 8, 16, 14, 30, 0, 0, time.Local)
+
+	editor := &lineEditor{MockEditor: MockEditor{Content: "standup done\n"}}
+	filePath, err := OpenDailyNote(dir, morning, editor, true)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if filepath.Base(filePath) != "2025-08-16.md" {
+		t.Errorf("Daily note = %s, want 2025-08-16.md", filepath.Base(filePath))
+	}
+
+	editor.Content = "reviewed PRs\n"
+	if _, err := OpenDailyNote(dir, afternoon, editor, true); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read daily note: %v", err)
+	}
+	expected := "# 2025-08-16\n\n## 09:05\n\nstandup done\n\n## 14:30\n\nreviewed PRs\n"
+	if string(content) != expected {
+		t.Errorf("Daily note content = %q, want %q", content, expected)
+	}
+
+	// Each entry opens on the empty line below its heading
+	if len(editor.Lines) != 2 || editor.Lines[0] != 4 || editor.Lines[1] != 8 {
+		t.Errorf("Editor opened at lines %v, want [4 8]", editor.Lines)
+	}
+}
+
+func TestOpenDailyNoteWithoutTrailingNewline(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2025, 8, 16, 14, 30, 0, 0, time.Local)
+	writeTestNoteContent(t, dir, "2025-08-16.md", "# 2025-08-16\n\nno newline")
+
+	filePath, err := OpenDailyNote(dir, day, &MockEditor{Content: "more\n"}, false)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, _ := os.ReadFile(filePath)
+	expected := "# 2025-08-16\n\nno newline\n\n## 14:30\n\nmore\n"
+	if string(content) != expected {
+		t.Errorf("Daily note content = %q, want %q", content, expected)
+	}
+}
+
+func TestOpenDailyNoteDiscardEmpty(t *testing.T) {
+	dir := t.TempDir()
+	day := time.Date(2025, 8, 16, 14, 30, 0, 0, time.Local)
+
+	// An empty entry in a new note removes the note
+	filePath, err := OpenDailyNote(dir, day, &MockEditor{}, true)
+	if !errors.Is(err, ErrNoteDiscarded) {
+		t.Fatalf("Expected ErrNoteDiscarded, got %v", err)
+	}
+	if _, err := os.Stat(filePath); !os.IsNotExist(err) {
+		t.Error("New daily note with an empty entry should be removed")
+	}
+
+	// An empty entry in an existing note is truncated away
+	original := "# 2025-08-16\n\n## 09:00\n\nkeep me\n"
+	writeTestNoteContent(t, dir, "2025-08-16.md", original)
+	if _, err := OpenDailyNote(dir, day, &MockEditor{}, true); !errors.Is(err, ErrNoteDiscarded) {
+		t.Fatalf("Expected ErrNoteDiscarded, got %v", err)
+	}
+	content, _ := os.ReadFile(filePath)
+	if string(content) != original {
+		t.Errorf("Daily note content = %q, want it restored to %q", content, original)
+	}
+
+	// The note is restored to its size on disk, without the newline ending
+	// an unterminated last line
+	unterminated := writeTestNoteContent(t, dir, "2025-08-17.md", "# 2025-08-17\n\nno newline")
+	if _, err := OpenDailyNote(dir, day.AddDate(0, 0, 1), &MockEditor{}, true); !errors.Is(err, ErrNoteDiscarded) {
+		t.Fatalf("Expected ErrNoteDiscarded, got %v", err)
+	}
+	if content, _ := os.ReadFile(unterminated); string(content) != "# 2025-08-17\n\nno newline" {
+		t.Errorf("Daily note content = %q, want it restored unterminated", content)
+	}
+
+	// Without discarding, the empty heading stays
+	if _, err := OpenDailyNote(dir, day, &MockEditor{}, false); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	content, _ = os.ReadFile(filePath)
+	if string(content) != original+"\n## 14:30\n\n" {
+		t.Errorf("Daily note content = %q, want empty entry kept", content)
+	}
+}
+
+func TestDailyOptionsEntryTime(t *testing.T) {
+	now := time.Date(2025, 8, 16, 14, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		opts     DailyOptions
+		expected time.Time
+	}{
+		{name: "today", opts: DailyOptions{}, expected: now},
+		{name: "yesterday", opts: DailyOptions{Yesterday: true}, expected: time.Date(2025, 8, 15, 14, 30, 0, 0, time.Local)},
+		{name: "date", opts: DailyOptions{Date: time.Date(2025, 7, 1, 0, 0, 0, 0, time.Local)}, expected: time.Date(2025, 7, 1, 14, 30, 0, 0, time.Local)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.opts.entryTime(now); !got.Equal(tt.expected) {
+				t.Errorf("entryTime() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseArgsDaily(t *testing.T) {
+	cmd, err := ParseArgs([]string{"scratch-note", "daily", "--date", "2025-07-01"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cmd.Type != CommandTypeDaily || cmd.Daily.Date.Format("2006-01-02") != "2025-07-01" {
+		t.Errorf("Unexpected command: %+v", cmd)
+	}
+
+	if _, err := ParseArgs([]string{"scratch-note", "daily", "--yesterday", "--date", "2025-07-01"}); err == nil {
+		t.Error("Expected error for --yesterday with --date")
+	}
+}
+
+func TestCollectNotesIncludesDailyNotes(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(), "2025-08-16.md", "2025-08-16_143045_meeting.md")
+
+	notes, err := collectNotes(dir)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	sortNotes(notes, SortCreated, false)
+
+	if len(notes) != 2 || !notes[1].Daily || notes[1].DisplayTitle() != "(daily)" {
+		t.Errorf("Expected the daily note after the meeting note, got %+v", notes)
+	}
+}
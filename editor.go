@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// DefaultEditor is used when neither the config nor the environment names an editor
+const DefaultEditor = "vi"
+
+// Placeholders substituted in editor command lines
+const (
+	placeholderFile = "{file}"
+	placeholderLine = "{line}"
+)
+
+// EditorLauncher interface for launching editors
+type EditorLauncher interface {
+	Launch(filePath string) error
+}
+
+// RealEditor implements EditorLauncher for real editor execution.
+// EditorName is a shell-style command line such as "code --wait" or
+// "nvim +{line} {file}"; the file path is appended when it has no {file}.
+type RealEditor struct {
+	EditorName string
+	// Line is substituted for {line}; zero means the first line
+	Line int
+}
+
+func (r *RealEditor) Launch(filePath string) error {
+	argv, err := r.Command(filePath)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err = cmd.Run()
+	if err != nil {
+		return &EditorError{Editor: r.EditorName, Err: err.Error()}
+	}
+	return nil
+}
+
+// Command returns the argv used to open filePath
+func (r *RealEditor) Command(filePath string) ([]string, error) {
+	argv, err := SplitCommandLine(r.EditorName)
+	if err != nil {
+		return nil, &EditorError{Editor: r.EditorName, Err: err.Error()}
+	}
+	if len(argv) == 0 {
+		return nil, &EditorError{Editor: r.EditorName, Err: "empty editor command"}
+	}
+
+	line := r.Line
+	if line < 1 {
+		line = 1
+	}
+
+	hasFile := false
+	for i, arg := range argv {
+		if strings.Contains(arg, placeholderFile) {
+			hasFile = true
+		}
+		arg = strings.ReplaceAll(arg, placeholderFile, filePath)
+		argv[i] = strings.ReplaceAll(arg, placeholderLine, strconv.Itoa(line))
+	}
+	if !hasFile {
+		argv = append(argv, filePath)
+	}
+
+	return argv, nil
+}
+
+// LineLauncher is an EditorLauncher that can place the cursor on a line
+type LineLauncher interface {
+	EditorLauncher
+	LaunchAt(filePath string, line int) error
+}
+
+// LaunchAt opens filePath with {line} set to line
+func (r *RealEditor) LaunchAt(filePath string, line int) error {
+	at := *r
+	at.Line = line
+	return at.Launch(filePath)
+}
+
+// ReaderSource implements EditorLauncher by writing the content of Reader
+// into the note instead of launching an editor, e.g. for piped input
+type ReaderSource struct {
+	Reader io.Reader
+}
+
+func (s *ReaderSource) Launch(filePath string) error {
+	file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open note: %v", err)
+	}
+
+	_, err = io.Copy(file, s.Reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write note content: %v", err)
+	}
+	return nil
+}
+
+// MessageSource returns a ReaderSource writing messages as separate
+// paragraphs, the way -m is given on the command line
+func MessageSource(messages []string) *ReaderSource {
+	return &ReaderSource{Reader: strings.NewReader(strings.Join(messages, "\n\n") + "\n")}
+}
+
+// isTerminal reports whether f is an interactive terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// EditorError represents an error when launching the editor
+type EditorError struct {
+	Editor string
+	Err    string
+}
+
+func (e *EditorError) Error() string {
+	return fmt.Sprintf("Editor '%s' not found: %s", e.Editor, e.Err)
+}
+
+// ResolveEditor returns the editor command line to use: the configured
+// editor, then $VISUAL, then $EDITOR, then DefaultEditor
+func ResolveEditor(configured string) string {
+	for _, editor := range []string{configured, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if strings.TrimSpace(editor) != "" {
+			return editor
+		}
+	}
+	return DefaultEditor
+}
+
+// SplitCommandLine splits s into arguments using shell-style quoting:
+// whitespace separates arguments, single quotes preserve everything
+// literally, double quotes allow backslash escapes of " and \, and a
+// backslash outside quotes escapes the next character.
+func SplitCommandLine(s string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+
+	const (
+		stateNone = iota
+		stateSingle
+		stateDouble
+	)
+	state := stateNone
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+
+		switch state {
+		case stateSingle:
+			if c == '\'' {
+				state = stateNone
+			} else {
+				current.WriteRune(c)
+			}
+			continue
+		case stateDouble:
+			switch {
+			case c == '"':
+				state = stateNone
+			case c == '\\' && i+1 < len(runes) && (runes[i+1] == '"' || runes[i+1] == '\\'):
+				i++
+				current.WriteRune(runes[i])
+			default:
+				current.WriteRune(c)
+			}
+			continue
+		}
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case c == '\'':
+			state = stateSingle
+			inArg = true
+		case c == '"':
+			state = stateDouble
+			inArg = true
+		case c == '\\':
+			if i+1 >= len(runes) {
+				return nil, fmt.Errorf("trailing backslash in %q", s)
+			}
+			i++
+			current.WriteRune(runes[i])
+			inArg = true
+		default:
+			current.WriteRune(c)
+			inArg = true
+		}
+	}
+
+	if state != stateNone {
+		return nil, fmt.Errorf("unterminated quote in %q", s)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitCommandLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    []string
+		expectError bool
+	}{
+		{name: "single word", input: "vi", expected: []string{"vi"}},
+		{name: "with arguments", input: "code --wait", expected: []string{"code", "--wait"}},
+		{name: "extra whitespace", input: "  emacsclient\t-t  ", expected: []string{"emacsclient", "-t"}},
+		{name: "double quotes", input: `"/Applications/Sublime Text/subl" -w`, expected: []string{"/Applications/Sublime Text/subl", "-w"}},
+		{name: "single quotes", input: `sh -c 'vi "$1"'`, expected: []string{"sh", "-c", `vi "$1"`}},
+		{name: "escaped space", input: `my\ editor {file}`, expected: []string{"my editor", "{file}"}},
+		{name: "escape in double quotes", input: `"a \"b\" \c"`, expected: []string{`a "b" \c`}},
+		{name: "empty quoted argument", input: `vi ""`, expected: []string{"vi", ""}},
+		{name: "empty string", input: "", expected: nil},
+		{name: "unterminated double quote", input: `vi "file`, expectError: true},
+		{name: "unterminated single quote", input: `vi 'file`, expectError: true},
+		{name: "trailing backslash", input: `vi \`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := SplitCommandLine(tt.input)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("SplitCommandLine(%q) expected error, got %q", tt.input, result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("SplitCommandLine(%q) unexpected error: %v", tt.input, err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("SplitCommandLine(%q) = %q, want %q", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRealEditorCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		editor   RealEditor
+		expected []string
+	}{
+		{
+			name:     "file appended",
+			editor:   RealEditor{EditorName: "code --wait"},
+			expected: []string{"code", "--wait", "/notes/a.md"},
+		},
+		{
+			name:     "file placeholder",
+			editor:   RealEditor{EditorName: "emacsclient -t {file}"},
+			expected: []string{"emacsclient", "-t", "/notes/a.md"},
+		},
+		{
+			name:     "line placeholder defaults to first line",
+			editor:   RealEditor{EditorName: "nvim +{line} {file}"},
+			expected: []string{"nvim", "+1", "/notes/a.md"},
+		},
+		{
+			name:     "line placeholder",
+			editor:   RealEditor{EditorName: "code --goto {file}:{line}", Line: 42},
+			expected: []string{"code", "--goto", "/notes/a.md:42"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.editor.Command("/notes/a.md")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Command() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRealEditorLaunchAt(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+
+	out := filepath.Join(t.TempDir(), "args")
+	editor := &RealEditor{EditorName: `sh -c 'echo "$1 $2" > ` + out + `' sh {line} {file}`}
+	if err := editor.LaunchAt("/notes/a.md", 7); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	args, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read editor arguments: %v", err)
+	}
+	if string(args) != "7 /notes/a.md\n" {
+		t.Errorf("Editor got %q, want line 7 and the file", args)
+	}
+	if editor.Line != 0 {
+		t.Errorf("LaunchAt should not change the editor's Line, got %d", editor.Line)
+	}
+}
+
+func TestRealEditorCommandInvalid(t *testing.T) {
+	for _, name := range []string{"", "   ", `vi "unterminated`} {
+		editor := &RealEditor{EditorName: name}
+		_, err := editor.Command("/notes/a.md")
+		if _, ok := err.(*EditorError); !ok {
+			t.Errorf("Command() with editor %q: expected EditorError, got %v", name, err)
+		}
+	}
+}
+
+func TestResolveEditor(t *testing.T) {
+	tests := []struct {
+		name       string
+		configured string
+		visual     string
+		editor     string
+		expected   string
+	}{
+		{name: "configured wins", configured: "nvim", visual: "code --wait", editor: "nano", expected: "nvim"},
+		{name: "visual before editor", visual: "code --wait", editor: "nano", expected: "code --wait"},
+		{name: "editor fallback", editor: "nano", expected: "nano"},
+		{name: "blank values ignored", configured: "  ", visual: " ", editor: "", expected: DefaultEditor},
+		{name: "default", expected: DefaultEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("VISUAL", tt.visual)
+			t.Setenv("EDITOR", tt.editor)
+
+			result := ResolveEditor(tt.configured)
+			if result != tt.expected {
+				t.Errorf("ResolveEditor(%q) = %q, want %q", tt.configured, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestReaderSourceWritesContent(t *testing.T) {
+	noteDir := t.TempDir()
+	source := &ReaderSource{Reader: strings.NewReader("line 1\nline 2\n")}
+
+	filePath, err := CreateScratchNote("incident", noteDir, time.Now(), source, NoteOptions{DiscardEmpty: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if string(content) != "line 1\nline 2\n" {
+		t.Errorf("Note content = %q, want piped input", content)
+	}
+}
+
+func TestReaderSourceEmptyInputDiscarded(t *testing.T) {
+	noteDir := t.TempDir()
+	source := &ReaderSource{Reader: strings.NewReader("")}
+
+	_, err := CreateScratchNote("", noteDir, time.Now(), source, NoteOptions{DiscardEmpty: true})
+	if !errors.Is(err, ErrNoteDiscarded) {
+		t.Errorf("Expected ErrNoteDiscarded for empty input, got %v", err)
+	}
+}
+
+func TestMessageSource(t *testing.T) {
+	noteDir := t.TempDir()
+
+	filePath, err := CreateScratchNote("", noteDir, time.Now(), MessageSource([]string{"first", "second"}), NoteOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if string(content) != "first\n\nsecond\n" {
+		t.Errorf("Note content = %q, want messages as paragraphs", content)
+	}
+}
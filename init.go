@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"scratch-note/config"
+)
+
+// defaultDirMode is the permission of notes directories created without
+// asking, and the default offered by the setup wizard
+const defaultDirMode os.FileMode = 0700
+
+// InitOptions holds the options of the init command
+type InitOptions struct {
+	// Force replaces an existing config file
+	Force bool
+}
+
+// SetupAnswers holds what the setup wizard asked for
+type SetupAnswers struct {
+	// Config is the configuration to write
+	Config *config.Config
+	// DirMode is the permission of the notes directory if it is created
+	DirMode os.FileMode
+}
+
+// RunSetup asks on out for the notes directory, the editor and the
+// permission of the notes directory, reading the answers from in. An empty
+// answer takes the default shown in brackets, taken from defaults when given.
+// Invalid answers, such as an editor that is not on PATH, are asked again.
+func RunSetup(in io.Reader, out io.Writer, defaults *config.Config) (SetupAnswers, error) {
+	cfg := config.GetDefaultConfig()
+	cfg.Editor = ResolveEditor("")
+	if defaults != nil {
+		copied := *defaults
+		if copied.ScratchNoteDir == "" {
+			copied.ScratchNoteDir = cfg.ScratchNoteDir
+		}
+		copied.Editor = ResolveEditor(copied.Editor)
+		cfg = &copied
+	}
+	answers := SetupAnswers{Config: cfg, DirMode: defaultDirMode}
+
+	scanner := bufio.NewScanner(in)
+	ask := func(question, fallback string, valid func(string) error) (string, error) {
+		for {
+			fmt.Fprintf(out, "%s [%s]: ", question, fallback)
+			if !scanner.Scan() {
+				fmt.Fprintln(out)
+				if err := scanner.Err(); err != nil {
+					return "", fmt.Errorf("failed to read answer: %v", err)
+				}
+				return "", fmt.Errorf("setup cancelled")
+			}
+
+			answer := strings.TrimSpace(scanner.Text())
+			if answer == "" {
+				answer = fallback
+			}
+			if err := valid(answer); err != nil {
+				fmt.Fprintf(out, "  %v\n", err)
+				continue
+			}
+			return answer, nil
+		}
+	}
+
+	var err error
+	cfg.ScratchNoteDir, err = ask("Notes directory", cfg.ScratchNoteDir, validateNotesDir)
+	if err != nil {
+		return SetupAnswers{}, err
+	}
+
+	cfg.Editor, err = ask("Editor", cfg.Editor, validateEditor)
+	if err != nil {
+		return SetupAnswers{}, err
+	}
+
+	mode, err := ask("Directory permissions", fmt.Sprintf("%04o", defaultDirMode), func(answer string) error {
+		_, err := parseDirMode(answer)
+		return err
+	})
+	if err != nil {
+		return SetupAnswers{}, err
+	}
+	answers.DirMode, _ = parseDirMode(mode)
+
+	return answers, nil
+}
+
+// validateNotesDir checks that dir can be used as the notes directory
+func validateNotesDir(dir string) error {
+	info, err := os.Stat(config.ExpandPath(dir))
+	if err == nil && !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	return nil
+}
+
+// validateEditor checks that the program of the editor command line is on PATH
+func validateEditor(editor string) error {
+	args, err := SplitCommandLine(editor)
+	if err != nil {
+		return fmt.Errorf("invalid editor command: %v", err)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no editor given")
+	}
+	if _, err := exec.LookPath(args[0]); err != nil {
+		return fmt.Errorf("editor %q was not found on PATH", args[0])
+	}
+	return nil
+}
+
+// parseDirMode parses an octal permission such as 700 or 0755
+func parseDirMode(s string) (os.FileMode, error) {
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("invalid permissions %q (want octal, e.g. 0700)", s)
+	}
+	if mode&0700 != 0700 {
+		return 0, fmt.Errorf("permissions %s would lock you out of the directory", s)
+	}
+	return os.FileMode(mode), nil
+}
+
+// InitConfig runs the setup wizard on in and out, creates the chosen notes
+// directory and writes the answers to configPath. An existing config file is
+// only replaced with opts.Force; its values are then offered as defaults and
+// its other settings are kept.
+func InitConfig(configPath string, opts InitOptions, in io.Reader, out io.Writer) (*config.Config, error) {
+	var existing *config.Config
+	if _, err := os.Stat(configPath); err == nil {
+		if !opts.Force {
+			return nil, fmt.Errorf("config file already exists: %s (use --force to replace it)", configPath)
+		}
+		// Keys with problems are asked for or dropped
+		if src, _, err := config.ParseConfig(configPath); err == nil {
+			existing = src.Config
+		}
+	}
+
+	answers, err := RunSetup(in, out, existing)
+	if err != nil {
+		return nil, err
+	}
+
+	notesDir := config.ExpandPath(answers.Config.ScratchNoteDir)
+	if _, err := os.Stat(notesDir); os.IsNotExist(err) {
+		if err := os.MkdirAll(notesDir, answers.DirMode); err != nil {
+			return nil, fmt.Errorf("failed to create scratch-note directory: %v", err)
+		}
+		// MkdirAll applies the umask
+		if err := os.Chmod(notesDir, answers.DirMode); err != nil {
+			return nil, fmt.Errorf("failed to set permissions of %s: %v", notesDir, err)
+		}
+		fmt.Fprintf(out, "Created scratch-note directory: %s\n", notesDir)
+	}
+
+	if err := config.CreateConfig(configPath, answers.Config); err != nil {
+		return nil, fmt.Errorf("failed to write config file: %v", err)
+	}
+	fmt.Fprintf(out, "Wrote config file: %s\n", configPath)
+
+	return answers.Config, nil
+}
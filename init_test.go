@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"scratch-note/config"
+)
+
+// fakeEditorOnPath puts an executable named name on an otherwise empty PATH
+func fakeEditorOnPath(t *testing.T, name string) {
+	t.Helper()
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake editor: %v", err)
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "")
+}
+
+func TestInitConfig(t *testing.T) {
+	fakeEditorOnPath(t, "nano")
+	configPath := filepath.Join(t.TempDir(), "scratch-note", "config.yaml")
+	notesDir := filepath.Join(t.TempDir(), "notes")
+
+	// The missing editor and invalid permissions are asked again
+	input := strings.Join([]string{notesDir, "nvim", "nano --wait", "888", "0750"}, "\n") + "\n"
+	var out bytes.Buffer
+	cfg, err := InitConfig(configPath, InitOptions{}, strings.NewReader(input), &out)
+	if err != nil {
+		t.Fatalf("InitConfig() unexpected error: %v\n%s", err, out.String())
+	}
+
+	if cfg.ScratchNoteDir != notesDir || cfg.Editor != "nano --wait" {
+		t.Errorf("InitConfig() = %+v", cfg)
+	}
+	for _, expected := range []string{`editor "nvim" was not found on PATH`, `invalid permissions "888"`, "Created scratch-note directory"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Output should contain %q:\n%s", expected, out.String())
+		}
+	}
+
+	info, err := os.Stat(notesDir)
+	if err != nil {
+		t.Fatalf("Notes directory was not created: %v", err)
+	}
+	if info.Mode().Perm() != 0750 {
+		t.Errorf("Notes directory mode = %o, want 750", info.Mode().Perm())
+	}
+
+	saved, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load written config: %v", err)
+	}
+	if saved.ScratchNoteDir != notesDir || saved.Editor != "nano --wait" {
+		t.Errorf("Written config = %+v", saved)
+	}
+
+	// An existing config is only replaced with --force
+	if _, err := InitConfig(configPath, InitOptions{}, strings.NewReader("\n\n\n"), &out); err == nil {
+		t.Error("Expected error for existing config file")
+	}
+	cfg, err = InitConfig(configPath, InitOptions{Force: true}, strings.NewReader("\n\n\n"), &out)
+	if err != nil {
+		t.Fatalf("InitConfig() with Force unexpected error: %v", err)
+	}
+	if cfg.ScratchNoteDir != notesDir || cfg.Editor != "nano --wait" {
+		t.Errorf("InitConfig() with Force should default to the existing values, got %+v", cfg)
+	}
+}
+
+func TestRunSetupDefaults(t *testing.T) {
+	fakeEditorOnPath(t, "vi")
+
+	answers, err := RunSetup(strings.NewReader("\n\n\n"), &bytes.Buffer{}, nil)
+	if err != nil {
+		t.Fatalf("RunSetup() unexpected error: %v", err)
+	}
+	if answers.Config.ScratchNoteDir != "~/scratch-notes" || answers.Config.Editor != "vi" || answers.DirMode != defaultDirMode {
+		t.Errorf("RunSetup() = %+v %+v", answers, answers.Config)
+	}
+}
+
+func TestRunSetupCancelled(t *testing.T) {
+	fakeEditorOnPath(t, "vi")
+
+	// Input ends while the editor is still missing
+	if _, err := RunSetup(strings.NewReader("/tmp/notes\nnvim\n"), &bytes.Buffer{}, nil); err == nil {
+		t.Error("Expected error when input ends")
+	}
+}
@@ -37,7 +37,7 @@ func TestIntegrationFullWorkflow(t *testing.T) {
 
 	// Test 1: Create note without title
 	mockEditor := &MockEditor{}
-	filePath1, err := CreateScratchNote("", notesDir, time.Now(), mockEditor)
+	filePath1, err := CreateScratchNote("", notesDir, time.Now(), mockEditor, NoteOptions{})
 	if err != nil {
 		t.Fatalf("Failed to create scratch note: %v", err)
 	}
@@ -55,7 +55,7 @@ func TestIntegrationFullWorkflow(t *testing.T) {
 
 	// Test 2: Create note with title
 	title := "integration-test-note"
-	filePath2, err := CreateScratchNote(title, notesDir, time.Now(), mockEditor)
+	filePath2, err := CreateScratchNote(title, notesDir, time.Now(), mockEditor, NoteOptions{})
 	if err != nil {
 		t.Fatalf("Failed to create titled scratch note: %v", err)
 	}
@@ -105,8 +105,9 @@ func TestIntegrationFullWorkflow(t *testing.T) {
 	}
 
 	// Verify config structure
-	if loadedConfig.Editor == "" {
-		t.Error("Config should have editor set")
+	// The default config leaves the editor to $VISUAL, $EDITOR or vi
+	if loadedConfig.Editor != "" || ResolveEditor(loadedConfig.Editor) == "" {
+		t.Errorf("Config editor = %q, want it resolved from the environment", loadedConfig.Editor)
 	}
 
 	if loadedConfig.ScratchNoteDir == "" {
@@ -131,7 +132,7 @@ func TestIntegrationErrorHandling(t *testing.T) {
 	nonExistentDir := filepath.Join(tempDir, "nonexistent")
 	mockEditor := &MockEditor{}
 
-	_, err := CreateScratchNote("", nonExistentDir, time.Now(), mockEditor)
+	_, err := CreateScratchNote("", nonExistentDir, time.Now(), mockEditor, NoteOptions{})
 	if err == nil {
 		t.Error("Expected error when directory doesn't exist")
 	}
@@ -148,7 +149,7 @@ func TestIntegrationErrorHandling(t *testing.T) {
 	}
 
 	failingEditor := &MockEditor{ShouldFail: true}
-	_, err = CreateScratchNote("", notesDir, time.Now(), failingEditor)
+	_, err = CreateScratchNote("", notesDir, time.Now(), failingEditor, NoteOptions{})
 	if err == nil {
 		t.Error("Expected error when editor fails")
 	}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"scratch-note/notes"
+)
+
+// LinksActionCheck is the action of the links command checking every link
+const LinksActionCheck = "check"
+
+// BacklinksOptions holds the options of the backlinks command
+type BacklinksOptions struct {
+	// Ref is a path, list index or title fragment of the note
+	Ref string
+	// JSON prints the links as JSON
+	JSON bool
+}
+
+// LinksOptions holds the options of the links command
+type LinksOptions struct {
+	// Action is LinksActionCheck
+	Action string
+	// JSON prints the report as JSON
+	JSON bool
+}
+
+// NoteLink is a [[wiki-link]] in a note and the notes its target resolves to
+type NoteLink struct {
+	// Source is the path of the linking note relative to the notes directory
+	Source string `json:"source"`
+	// Line is the line of the link in Source
+	Line int `json:"line"`
+	// Text is the link as written, brackets included
+	Text string `json:"text"`
+	// Target is the note name in the link, without heading or alias
+	Target string `json:"target"`
+	// Matches are the paths of the notes Target resolves to; more than one
+	// makes the link ambiguous and none makes it broken
+	Matches []string `json:"matches,omitempty"`
+}
+
+// LinkReport is the result of links check
+type LinkReport struct {
+	// Broken are the links matching no note
+	Broken []NoteLink `json:"broken"`
+	// Ambiguous are the links matching several notes
+	Ambiguous []NoteLink `json:"ambiguous"`
+	// Orphans are the notes no other note links to, daily notes aside
+	Orphans []string `json:"orphans"`
+}
+
+// linkTarget holds the names a note can be linked by
+type linkTarget struct {
+	// rel is the path of the note relative to the notes directory
+	rel string
+	// names are the path, filename and timestamp of the note, in lower case
+	names []string
+	// title is the slug of the note in lower case, empty for untitled notes
+	title string
+	daily bool
+}
+
+// linkNames returns the names other than its title that link to the note
+// at rel: its path and filename, each with and without the extension, and
+// the timestamp of a timestamped note, always in this order
+func linkNames(rel string) []string {
+	base := path.Base(rel)
+	ext := path.Ext(base)
+	names := []string{rel, strings.TrimSuffix(rel, ext), base, strings.TrimSuffix(base, ext)}
+	if n, format, err := parseNoteName(rel); err == nil {
+		names = append(names, format.Timestamp(n))
+	}
+	return names
+}
+
+// newLinkTarget returns the names the note at rel, with the given entry,
+// can be linked by
+func newLinkTarget(rel string, entry NoteEntry) linkTarget {
+	target := linkTarget{
+		rel:   rel,
+		title: strings.ToLower(entry.Title),
+		daily: entry.Daily,
+	}
+	for _, name := range linkNames(rel) {
+		target.names = append(target.names, strings.ToLower(name))
+	}
+	return target
+}
+
+// resolveLink returns the paths of the notes a wiki-link target names. A
+// target is matched case-insensitively against the path, filename and
+// timestamp of each note, and only when none matches against the titles.
+func resolveLink(targets []linkTarget, target string) []string {
+	name := strings.ToLower(strings.TrimSpace(target))
+	var matches []string
+	for _, t := range targets {
+		if slices.Contains(t.names, name) {
+			matches = append(matches, t.rel)
+		}
+	}
+	if len(matches) > 0 {
+		return matches
+	}
+
+	slug := strings.ToLower(noteNames.Slug(target))
+	for _, t := range targets {
+		if slug != "" && t.title == slug {
+			matches = append(matches, t.rel)
+		}
+	}
+	return matches
+}
+
+// collectLinks returns the notes in directory as link targets, and every
+// wiki-link in them resolved against those notes
+func collectLinks(directory string) ([]linkTarget, []NoteLink, error) {
+	entries, err := collectNotes(directory)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	targets := make([]linkTarget, 0, len(entries))
+	for _, entry := range entries {
+		rel, err := filepath.Rel(directory, entry.Path)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets = append(targets, newLinkTarget(filepath.ToSlash(rel), entry))
+	}
+
+	var links []NoteLink
+	for i, entry := range entries {
+		content, err := os.ReadFile(entry.Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read note: %v", err)
+		}
+		for _, link := range notes.WikiLinks(content) {
+			links = append(links, NoteLink{
+				Source:  targets[i].rel,
+				Line:    link.Line,
+				Text:    string(content[link.Start:link.End]),
+				Target:  link.Target,
+				Matches: resolveLink(targets, link.Target),
+			})
+		}
+	}
+	return targets, links, nil
+}
+
+// Backlinks returns the links in the notes of directory that resolve to the
+// note ref refers to and no other
+func Backlinks(directory, ref string) ([]NoteLink, error) {
+	notePath, err := ResolveNote(directory, ref)
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(directory)
+	if err != nil {
+		return nil, err
+	}
+	if notePath, err = filepath.Abs(notePath); err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, notePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("%s is not in the scratch-note directory", notePath)
+	}
+	rel = filepath.ToSlash(rel)
+
+	_, links, err := collectLinks(directory)
+	if err != nil {
+		return nil, err
+	}
+	var backlinks []NoteLink
+	for _, link := range links {
+		if len(link.Matches) == 1 && link.Matches[0] == rel {
+			backlinks = append(backlinks, link)
+		}
+	}
+	return backlinks, nil
+}
+
+// ListBacklinks writes the links to the note opts.Ref refers to to w, one
+// per line as source:line: [[link]]
+func ListBacklinks(directory string, opts BacklinksOptions, w io.Writer) error {
+	backlinks, err := Backlinks(directory, opts.Ref)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		if backlinks == nil {
+			backlinks = []NoteLink{}
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(backlinks)
+	}
+	if len(backlinks) == 0 {
+		fmt.Fprintln(w, "No notes link to this note")
+		return nil
+	}
+	for _, link := range backlinks {
+		fmt.Fprintf(w, "%s:%d: %s\n", link.Source, link.Line, link.Text)
+	}
+	return nil
+}
+
+// CheckLinks returns the broken and ambiguous links in the notes of
+// directory, and the notes no other note links to
+func CheckLinks(directory string) (LinkReport, error) {
+	report := LinkReport{Broken: []NoteLink{}, Ambiguous: []NoteLink{}, Orphans: []string{}}
+	targets, links, err := collectLinks(directory)
+	if err != nil {
+		return report, err
+	}
+
+	linked := map[string]bool{}
+	for _, link := range links {
+		if len(link.Matches) == 0 {
+			report.Broken = append(report.Broken, link)
+		} else if len(link.Matches) > 1 {
+			report.Ambiguous = append(report.Ambiguous, link)
+		}
+		// A link that may mean a note keeps it from being reported as an
+		// orphan, while links from a note to itself do not count
+		for _, match := range link.Matches {
+			if match != link.Source {
+				linked[match] = true
+			}
+		}
+	}
+
+	for _, target := range targets {
+		if !target.daily && !linked[target.rel] {
+			report.Orphans = append(report.Orphans, target.rel)
+		}
+	}
+	return report, nil
+}
+
+// WriteLinkReport checks the links in the notes of directory and writes the
+// report to w. It returns the number of broken and ambiguous links.
+func WriteLinkReport(directory string, opts LinksOptions, w io.Writer) (int, error) {
+	report, err := CheckLinks(directory)
+	if err != nil {
+		return 0, err
+	}
+	problems := len(report.Broken) + len(report.Ambiguous)
+
+	if opts.JSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return problems, encoder.Encode(report)
+	}
+
+	if len(report.Broken) > 0 {
+		fmt.Fprintln(w, "Broken links:")
+		for _, link := range report.Broken {
+			fmt.Fprintf(w, "  %s:%d: %s\n", link.Source, link.Line, link.Text)
+		}
+	}
+	if len(report.Ambiguous) > 0 {
+		fmt.Fprintln(w, "Ambiguous links:")
+		for _, link := range report.Ambiguous {
+			fmt.Fprintf(w, "  %s:%d: %s could be %s\n", link.Source, link.Line, link.Text, strings.Join(link.Matches, ", "))
+		}
+	}
+	if len(report.Orphans) > 0 {
+		fmt.Fprintln(w, "Orphan notes:")
+		for _, rel := range report.Orphans {
+			fmt.Fprintf(w, "  %s\n", rel)
+		}
+	}
+	if problems == 0 && len(report.Orphans) == 0 {
+		fmt.Fprintln(w, "All links resolve and every note is linked")
+	}
+	return problems, nil
+}
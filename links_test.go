@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeLinkedNotes writes notes linking to each other in several ways
+func writeLinkedNotes(t *testing.T, dir string) {
+	t.Helper()
+	writeTestNoteContent(t, dir, "2025/09/2025-09-01_100000_disk-full.md", "Root cause in [[Postmortem]].\n")
+	writeTestNoteContent(t, dir, "2025/09/2025-09-02_100000_postmortem.md", "See [[2025-09-01_100000]] and [[2025-09-01_100000_disk-full.md#Fix|the fix]].\n")
+	writeTestNoteContent(t, dir, "2025/09/2025-09-03_100000_standup.md", "first\n")
+	writeTestNoteContent(t, dir, "2025/09/2025-09-04_100000_standup.md", "second\n")
+	writeTestNoteContent(t, dir, "2025/09/2025-09-05_100000_retro.md", "After [[standup]], [[missing note]].\n`[[in code]]` [[retro]]\n")
+	writeTestNoteContent(t, dir, "2025/09/2025-09-06.md", "Daily, links [[2025-09-03_100000_standup]]\n")
+}
+
+func TestResolveLink(t *testing.T) {
+	useLayout(t, "monthly")
+	dir := t.TempDir()
+	writeLinkedNotes(t, dir)
+	targets, _, err := collectLinks(dir)
+	if err != nil {
+		t.Fatalf("collectLinks() unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		target   string
+		expected []string
+	}{
+		{"2025-09-01_100000_disk-full", []string{"2025/09/2025-09-01_100000_disk-full.md"}},
+		{"2025-09-01_100000_Disk-Full.md", []string{"2025/09/2025-09-01_100000_disk-full.md"}},
+		{"2025/09/2025-09-01_100000_disk-full", []string{"2025/09/2025-09-01_100000_disk-full.md"}},
+		{"2025-09-01_100000", []string{"2025/09/2025-09-01_100000_disk-full.md"}},
+		{"Disk full", []string{"2025/09/2025-09-01_100000_disk-full.md"}},
+		{"2025-09-06", []string{"2025/09/2025-09-06.md"}},
+		{"standup", []string{"2025/09/2025-09-03_100000_standup.md", "2025/09/2025-09-04_100000_standup.md"}},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			got := resolveLink(targets, tt.target)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("resolveLink(%q) = %v, want %v", tt.target, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestBacklinks(t *testing.T) {
+	useLayout(t, "monthly")
+	dir := t.TempDir()
+	writeLinkedNotes(t, dir)
+
+	var out bytes.Buffer
+	if err := ListBacklinks(dir, BacklinksOptions{Ref: "disk"}, &out); err != nil {
+		t.Fatalf("ListBacklinks() unexpected error: %v", err)
+	}
+	expected := "2025/09/2025-09-02_100000_postmortem.md:1: [[2025-09-01_100000]]\n" +
+		"2025/09/2025-09-02_100000_postmortem.md:1: [[2025-09-01_100000_disk-full.md#Fix|the fix]]\n"
+	if out.String() != expected {
+		t.Errorf("ListBacklinks() =\n%s\nwant\n%s", out.String(), expected)
+	}
+
+	// The ambiguous [[standup]] is not a backlink of either standup
+	backlinks, err := Backlinks(dir, "2025-09-04")
+	if err != nil || len(backlinks) != 0 {
+		t.Errorf("Backlinks() = %+v, %v; want none", backlinks, err)
+	}
+
+	out.Reset()
+	if err := ListBacklinks(dir, BacklinksOptions{Ref: "2025-09-03", JSON: true}, &out); err != nil {
+		t.Fatalf("ListBacklinks() unexpected error: %v", err)
+	}
+	var links []NoteLink
+	if err := json.Unmarshal(out.Bytes(), &links); err != nil {
+		t.Fatalf("ListBacklinks() wrote invalid JSON: %v\n%s", err, out.String())
+	}
+	if len(links) != 1 || links[0].Source != "2025/09/2025-09-06.md" {
+		t.Errorf("ListBacklinks() JSON = %+v", links)
+	}
+}
+
+func TestCheckLinks(t *testing.T) {
+	useLayout(t, "monthly")
+	dir := t.TempDir()
+	writeLinkedNotes(t, dir)
+
+	report, err := CheckLinks(dir)
+	if err != nil {
+		t.Fatalf("CheckLinks() unexpected error: %v", err)
+	}
+
+	if len(report.Broken) != 1 || report.Broken[0].Text != "[[missing note]]" || report.Broken[0].Line != 1 {
+		t.Errorf("Broken = %+v, want [[missing note]] on line 1", report.Broken)
+	}
+	if len(report.Ambiguous) != 1 || report.Ambiguous[0].Target != "standup" || len(report.Ambiguous[0].Matches) != 2 {
+		t.Errorf("Ambiguous = %+v, want [[standup]] with 2 matches", report.Ambiguous)
+	}
+	// The retro only links to itself and the daily note is never an orphan
+	expected := []string{"2025/09/2025-09-05_100000_retro.md"}
+	if !reflect.DeepEqual(report.Orphans, expected) {
+		t.Errorf("Orphans = %v, want %v", report.Orphans, expected)
+	}
+
+	var out bytes.Buffer
+	count, err := WriteLinkReport(dir, LinksOptions{Action: LinksActionCheck}, &out)
+	if err != nil || count != 2 {
+		t.Errorf("WriteLinkReport() = %d, %v; want 2 problems", count, err)
+	}
+	for _, want := range []string{
+		"Broken links:\n  2025/09/2025-09-05_100000_retro.md:1: [[missing note]]\n",
+		"[[standup]] could be 2025/09/2025-09-03_100000_standup.md, 2025/09/2025-09-04_100000_standup.md\n",
+		"Orphan notes:\n  2025/09/2025-09-05_100000_retro.md\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("WriteLinkReport() output missing %q:\n%s", want, out.String())
+		}
+	}
+
+	out.Reset()
+	if _, err := WriteLinkReport(dir, LinksOptions{Action: LinksActionCheck, JSON: true}, &out); err != nil {
+		t.Fatalf("WriteLinkReport() unexpected error: %v", err)
+	}
+	var decoded LinkReport
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+		t.Fatalf("WriteLinkReport() wrote invalid JSON: %v\n%s", err, out.String())
+	}
+	if !reflect.DeepEqual(decoded, report) {
+		t.Errorf("JSON report = %+v, want %+v", decoded, report)
+	}
+}
+
+func TestCheckLinksClean(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-09-01_100000_a.md", "[[b]]\n")
+	writeTestNoteContent(t, dir, "2025-09-02_100000_b.md", "[[a]]\n")
+
+	var out bytes.Buffer
+	count, err := WriteLinkReport(dir, LinksOptions{Action: LinksActionCheck}, &out)
+	if err != nil || count != 0 {
+		t.Errorf("WriteLinkReport() = %d, %v; want no problems", count, err)
+	}
+	if out.String() != "All links resolve and every note is linked\n" {
+		t.Errorf("WriteLinkReport() = %q", out.String())
+	}
+
+	out.Reset()
+	if _, err := WriteLinkReport(dir, LinksOptions{Action: LinksActionCheck, JSON: true}, &out); err != nil {
+		t.Fatalf("WriteLinkReport() unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), `"broken": []`) {
+		t.Errorf("JSON report should have empty lists, not null:\n%s", out.String())
+	}
+}
+
+func TestParseArgsLinks(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    Command
+		expectError bool
+	}{
+		{
+			name:     "backlinks",
+			args:     []string{"scratch-note", "backlinks", "disk", "--json"},
+			expected: Command{Type: CommandTypeBacklinks, Backlinks: BacklinksOptions{Ref: "disk", JSON: true}},
+		},
+		{
+			name:     "links checks by default",
+			args:     []string{"scratch-note", "links"},
+			expected: Command{Type: CommandTypeLinks, Links: LinksOptions{Action: LinksActionCheck}},
+		},
+		{
+			name:     "links check json",
+			args:     []string{"scratch-note", "links", "check", "--json"},
+			expected: Command{Type: CommandTypeLinks, Links: LinksOptions{Action: LinksActionCheck, JSON: true}},
+		},
+		{name: "backlinks without note", args: []string{"scratch-note", "backlinks"}, expectError: true},
+		{name: "unknown links action", args: []string{"scratch-note", "links", "fix"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseArgs(tt.args)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cmd, tt.expected) {
+				t.Errorf("ParseArgs() = %+v, want %+v", cmd, tt.expected)
+			}
+		})
+	}
+}
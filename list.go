@@ -0,0 +1,355 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"scratch-note/utils"
+)
+
+// Sort orders supported by the list command
+const (
+	SortCreated  = "created"
+	SortTitle    = "title"
+	SortModified = "modified"
+)
+
+// Output formats supported by the list command
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatPlain = "plain"
+)
+
+// ListOptions holds the options of the list command
+type ListOptions struct {
+	Sort    string
+	Reverse bool
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+	Format  string
+	// Tags keeps notes having each tag and none of the !tags
+	Tags []string
+}
+
+// NoteEntry describes a note file found in the notes directory
+type NoteEntry struct {
+	Path     string
+	Title    string
+	Created  time.Time
+	Modified time.Time
+	// Seq orders notes created in the same second, see utils.NoteName
+	Seq int
+	// Daily marks a daily note, whose Created is the start of its day
+	Daily bool
+	// Tags are only read when listing by tag or as JSON
+	Tags []string
+}
+
+// DisplayTitle returns the title shown for the note in listings
+func (n NoteEntry) DisplayTitle() string {
+	switch {
+	case n.Title != "":
+		return n.Title
+	case n.Daily:
+		return "(daily)"
+	default:
+		return "(untitled)"
+	}
+}
+
+// noteEntryJSON is the JSON representation of a listed note
+type noteEntryJSON struct {
+	Index    int       `json:"index"`
+	Path     string    `json:"path"`
+	File     string    `json:"file"`
+	Title    string    `json:"title"`
+	Created  time.Time `json:"created"`
+	Modified time.Time `json:"modified"`
+	Daily    bool      `json:"daily,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+}
+
+// dateFlag is a flag.Value accepting YYYY-MM-DD or RFC 3339 timestamps.
+// A bare date given to an "until" flag covers the whole day.
+type dateFlag struct {
+	t        *time.Time
+	endOfDay bool
+}
+
+func (d dateFlag) String() string {
+	if d.t == nil || d.t.IsZero() {
+		return ""
+	}
+	return d.t.Format(time.RFC3339)
+}
+
+func (d dateFlag) Set(value string) error {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		*d.t = t
+		return nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return fmt.Errorf("invalid date %q (want YYYY-MM-DD or RFC 3339)", value)
+	}
+	if d.endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	*d.t = t
+	return nil
+}
+
+// validateListOptions checks the values given to the list command
+func validateListOptions(opts ListOptions) error {
+	switch opts.Sort {
+	case SortCreated, SortTitle, SortModified:
+	default:
+		return fmt.Errorf("invalid sort order: %s (want created, title or modified)", opts.Sort)
+	}
+
+	switch opts.Format {
+	case FormatTable, FormatJSON, FormatPlain:
+	default:
+		return fmt.Errorf("invalid format: %s (want table, json or plain)", opts.Format)
+	}
+
+	if opts.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+
+	if !opts.Since.IsZero() && !opts.Until.IsZero() && opts.Until.Before(opts.Since) {
+		return fmt.Errorf("--until must not be before --since")
+	}
+
+	return nil
+}
+
+// parseNoteName parses rel, the path of a note relative to the notes
+// directory, with the configured filename format. Notes saved before
+// filename_template or filename_extension were changed are parsed with the
+// default format instead, so they are still found until they are migrated.
+// It returns the format that parsed the name.
+func parseNoteName(rel string) (utils.NoteName, *utils.FileNameFormat, error) {
+	name, err := noteNames.Parse(rel)
+	if err == nil {
+		return name, noteNames, nil
+	}
+	if legacy, legacyErr := utils.DefaultFileNameFormat().Parse(rel); legacyErr == nil {
+		return legacy, utils.DefaultFileNameFormat(), nil
+	}
+	return utils.NoteName{}, nil, err
+}
+
+// parseDailyNoteName is like parseNoteName for daily notes
+func parseDailyNoteName(rel string) (time.Time, error) {
+	day, err := noteNames.ParseDailyFileName(rel)
+	if err == nil {
+		return day, nil
+	}
+	if legacy, legacyErr := utils.DefaultFileNameFormat().ParseDailyFileName(rel); legacyErr == nil {
+		return legacy, nil
+	}
+	return time.Time{}, err
+}
+
+// collectNotes returns every note in directory and its subdirectories whose
+// name, relative to directory, can be parsed, including daily notes. Hidden
+// directories are skipped.
+func collectNotes(directory string) ([]NoteEntry, error) {
+	if _, err := os.Stat(directory); err != nil {
+		return nil, fmt.Errorf("failed to read scratch-note directory: %v", err)
+	}
+
+	var notes []NoteEntry
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != directory && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		rel, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		note := NoteEntry{Path: path}
+		if name, _, err := parseNoteName(rel); err == nil {
+			note.Title, note.Created, note.Seq = name.Slug, name.Time, name.Seq
+		} else if day, err := parseDailyNoteName(rel); err == nil {
+			note.Created, note.Daily = day, true
+		} else {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %v", rel, err)
+		}
+		note.Modified = info.ModTime()
+
+		notes = append(notes, note)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scratch-note directory: %v", err)
+	}
+
+	return notes, nil
+}
+
+// sortNotes orders notes in place. Time-based orders put the newest note
+// first, title order is alphabetical; reverse flips either.
+func sortNotes(notes []NoteEntry, order string, reverse bool) {
+	less := func(a, b NoteEntry) bool {
+		switch order {
+		case SortTitle:
+			at, bt := strings.ToLower(a.Title), strings.ToLower(b.Title)
+			if at != bt {
+				return at < bt
+			}
+			return a.Created.Before(b.Created)
+		case SortModified:
+			if !a.Modified.Equal(b.Modified) {
+				return a.Modified.After(b.Modified)
+			}
+		}
+		if !a.Created.Equal(b.Created) {
+			return a.Created.After(b.Created)
+		}
+		if a.Seq != b.Seq {
+			return a.Seq > b.Seq
+		}
+		return a.Path > b.Path
+	}
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		if reverse {
+			return less(notes[j], notes[i])
+		}
+		return less(notes[i], notes[j])
+	})
+}
+
+// filterNotes keeps the notes created within the since/until window
+func filterNotes(notes []NoteEntry, since, until time.Time) []NoteEntry {
+	var filtered []NoteEntry
+	for _, note := range notes {
+		if !since.IsZero() && note.Created.Before(since) {
+			continue
+		}
+		if !until.IsZero() && note.Created.After(until) {
+			continue
+		}
+		filtered = append(filtered, note)
+	}
+	return filtered
+}
+
+// filterTags reads the tags of notes and keeps those matching filters, see
+// matchTags
+func filterTags(notes []NoteEntry, filters []string) ([]NoteEntry, error) {
+	var filtered []NoteEntry
+	for _, note := range notes {
+		tags, err := readNoteTags(note.Path)
+		if err != nil {
+			return nil, err
+		}
+		note.Tags = tags
+		if matchTags(tags, filters) {
+			filtered = append(filtered, note)
+		}
+	}
+	return filtered, nil
+}
+
+// selectNotes collects, filters, sorts and limits the notes in directory
+func selectNotes(directory string, opts ListOptions) ([]NoteEntry, error) {
+	if err := validateListOptions(opts); err != nil {
+		return nil, err
+	}
+
+	notes, err := collectNotes(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	notes = filterNotes(notes, opts.Since, opts.Until)
+	if len(opts.Tags) > 0 || opts.Format == FormatJSON {
+		if notes, err = filterTags(notes, opts.Tags); err != nil {
+			return nil, err
+		}
+	}
+	sortNotes(notes, opts.Sort, opts.Reverse)
+
+	if opts.Limit > 0 && len(notes) > opts.Limit {
+		notes = notes[:opts.Limit]
+	}
+
+	return notes, nil
+}
+
+// ListNotes writes the notes in directory to w using the given options
+func ListNotes(directory string, opts ListOptions, w io.Writer) error {
+	notes, err := selectNotes(directory, opts)
+	if err != nil {
+		return err
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		return writeNotesJSON(w, notes)
+	case FormatPlain:
+		for _, note := range notes {
+			fmt.Fprintln(w, note.Path)
+		}
+		return nil
+	default:
+		return writeNotesTable(w, notes)
+	}
+}
+
+func writeNotesTable(w io.Writer, notes []NoteEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tCREATED\tTITLE\tFILE")
+	for i, note := range notes {
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", i+1, note.Created.Format("2006-01-02 15:04:05"), note.DisplayTitle(), filepath.Base(note.Path))
+	}
+	return tw.Flush()
+}
+
+func writeNotesJSON(w io.Writer, notes []NoteEntry) error {
+	out := make([]noteEntryJSON, 0, len(notes))
+	for i, note := range notes {
+		out = append(out, noteEntryJSON{
+			Index:    i + 1,
+			Path:     note.Path,
+			File:     filepath.Base(note.Path),
+			Title:    note.Title,
+			Created:  note.Created,
+			Modified: note.Modified,
+			Daily:    note.Daily,
+			Tags:     note.Tags,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTestNotes creates the named files in dir and returns dir
+func writeTestNotes(t *testing.T, dir string, names ...string) string {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(""), 0644); err != nil {
+			t.Fatalf("Failed to write test note %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func defaultListOptions() ListOptions {
+	return ListOptions{Sort: SortCreated, Format: FormatPlain}
+}
+
+func TestListNotesCustomFormat(t *testing.T) {
+	useNoteNames(t, "{date:2006-01-02}/{time:150405}-{slug}", ".org", "")
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-16/143045-beta.org",
+		"2025-08-17/090000.org",
+		"2025-08-17/090000-2.org",
+		"2025-08-18.org",
+		"2025-08-16_143045_old.md",
+		".trash/2025-08-19/100000-gone.org",
+	)
+
+	var buf bytes.Buffer
+	if err := ListNotes(dir, defaultListOptions(), &buf); err != nil {
+		t.Fatalf("ListNotes() unexpected error: %v", err)
+	}
+
+	// The note saved in the default format before the template changed is
+	// still listed
+	expected := []string{"2025-08-18.org", "2025-08-17/090000-2.org", "2025-08-17/090000.org", "2025-08-16_143045_old.md", "2025-08-16/143045-beta.org"}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		rel, _ := filepath.Rel(dir, line)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("ListNotes() = %v, want %v", got, expected)
+	}
+}
+
+func TestListNotesSorting(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-16_143045_beta.md",
+		"2025-08-17_090000_alpha.md",
+		"2025-08-15_120000.md",
+		"README.md",
+	)
+
+	// Give each note a modification time in the opposite order of creation
+	mtimes := map[string]time.Time{
+		"2025-08-15_120000.md":       time.Date(2025, 9, 3, 0, 0, 0, 0, time.Local),
+		"2025-08-16_143045_beta.md":  time.Date(2025, 9, 2, 0, 0, 0, 0, time.Local),
+		"2025-08-17_090000_alpha.md": time.Date(2025, 9, 1, 0, 0, 0, 0, time.Local),
+	}
+	for name, mtime := range mtimes {
+		if err := os.Chtimes(filepath.Join(dir, name), mtime, mtime); err != nil {
+			t.Fatalf("Failed to set mtime: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		sort     string
+		reverse  bool
+		expected []string
+	}{
+		{
+			name:     "created newest first",
+			sort:     SortCreated,
+			expected: []string{"2025-08-17_090000_alpha.md", "2025-08-16_143045_beta.md", "2025-08-15_120000.md"},
+		},
+		{
+			name:     "created reversed",
+			sort:     SortCreated,
+			reverse:  true,
+			expected: []string{"2025-08-15_120000.md", "2025-08-16_143045_beta.md", "2025-08-17_090000_alpha.md"},
+		},
+		{
+			name:     "title alphabetical",
+			sort:     SortTitle,
+			expected: []string{"2025-08-15_120000.md", "2025-08-17_090000_alpha.md", "2025-08-16_143045_beta.md"},
+		},
+		{
+			name:     "modified newest first",
+			sort:     SortModified,
+			expected: []string{"2025-08-15_120000.md", "2025-08-16_143045_beta.md", "2025-08-17_090000_alpha.md"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := defaultListOptions()
+			opts.Sort = tt.sort
+			opts.Reverse = tt.reverse
+
+			var buf bytes.Buffer
+			if err := ListNotes(dir, opts, &buf); err != nil {
+				t.Fatalf("ListNotes failed: %v", err)
+			}
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				got = append(got, filepath.Base(line))
+			}
+
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("ListNotes order = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestListNotesFilterAndLimit(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-14_100000_a.md",
+		"2025-08-15_100000_b.md",
+		"2025-08-16_100000_c.md",
+		"2025-08-17_100000_d.md",
+	)
+
+	opts := defaultListOptions()
+	if err := (dateFlag{t: &opts.Since}).Set("2025-08-15"); err != nil {
+		t.Fatalf("Failed to set since: %v", err)
+	}
+	if err := (dateFlag{t: &opts.Until, endOfDay: true}).Set("2025-08-16"); err != nil {
+		t.Fatalf("Failed to set until: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := ListNotes(dir, opts, &buf); err != nil {
+		t.Fatalf("ListNotes failed: %v", err)
+	}
+	output := buf.String()
+	if !strings.Contains(output, "_b.md") || !strings.Contains(output, "_c.md") {
+		t.Errorf("Output should contain notes within range:\n%s", output)
+	}
+	if strings.Contains(output, "_a.md") || strings.Contains(output, "_d.md") {
+		t.Errorf("Output should not contain notes outside range:\n%s", output)
+	}
+
+	opts = defaultListOptions()
+	opts.Limit = 1
+	buf.Reset()
+	if err := ListNotes(dir, opts, &buf); err != nil {
+		t.Fatalf("ListNotes failed: %v", err)
+	}
+	if strings.TrimSpace(buf.String()) != filepath.Join(dir, "2025-08-17_100000_d.md") {
+		t.Errorf("Limit 1 should print only the newest note, got:\n%s", buf.String())
+	}
+}
+
+func TestListNotesFormats(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-16_143045_meeting-notes.md",
+		"2025-08-16_150000.md",
+	)
+
+	t.Run("table", func(t *testing.T) {
+		opts := defaultListOptions()
+		opts.Format = FormatTable
+
+		var buf bytes.Buffer
+		if err := ListNotes(dir, opts, &buf); err != nil {
+			t.Fatalf("ListNotes failed: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("Table should have a header and 2 rows, got:\n%s", buf.String())
+		}
+		if !strings.HasPrefix(lines[0], "#") {
+			t.Errorf("First line should be the header, got %q", lines[0])
+		}
+		if !strings.Contains(lines[1], "(untitled)") {
+			t.Errorf("Untitled note should be shown as (untitled): %q", lines[1])
+		}
+		if !strings.Contains(lines[2], "meeting-notes") {
+			t.Errorf("Titled note should show its title: %q", lines[2])
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		opts := defaultListOptions()
+		opts.Format = FormatJSON
+
+		var buf bytes.Buffer
+		if err := ListNotes(dir, opts, &buf); err != nil {
+			t.Fatalf("ListNotes failed: %v", err)
+		}
+
+		var entries []noteEntryJSON
+		if err := json.Unmarshal(buf.Bytes(), &entries); err != nil {
+			t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+		}
+		if len(entries) != 2 {
+			t.Fatalf("Expected 2 entries, got %d", len(entries))
+		}
+		if entries[1].Title != "meeting-notes" || entries[1].Index != 2 {
+			t.Errorf("Unexpected second entry: %+v", entries[1])
+		}
+	})
+}
+
+func TestListNotesInvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name string
+		opts ListOptions
+	}{
+		{name: "unknown sort", opts: ListOptions{Sort: "size", Format: FormatTable}},
+		{name: "unknown format", opts: ListOptions{Sort: SortCreated, Format: "xml"}},
+		{name: "negative limit", opts: ListOptions{Sort: SortCreated, Format: FormatTable, Limit: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ListNotes(dir, tt.opts, &bytes.Buffer{}); err == nil {
+				t.Error("Expected error but got none")
+			}
+		})
+	}
+}
+
+func TestParseArgsList(t *testing.T) {
+	cmd, err := ParseArgs([]string{"scratch-note", "list", "--sort", "title", "--limit", "5", "--format", "json", "--since", "2025-08-01"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cmd.Type != CommandTypeList {
+		t.Fatalf("Command type = %v, want %v", cmd.Type, CommandTypeList)
+	}
+
+	expectedSince := time.Date(2025, 8, 1, 0, 0, 0, 0, time.Local)
+	if cmd.List.Sort != SortTitle || cmd.List.Limit != 5 || cmd.List.Format != FormatJSON || !cmd.List.Since.Equal(expectedSince) {
+		t.Errorf("Unexpected list options: %+v", cmd.List)
+	}
+
+	if _, err := ParseArgs([]string{"scratch-note", "list", "--format", "xml"}); err == nil {
+		t.Error("Expected error for invalid format")
+	}
+
+	if _, err := ParseArgs([]string{"scratch-note", "list", "--since", "yesterday"}); err == nil {
+		t.Error("Expected error for invalid date")
+	}
+}
@@ -1,116 +1,97 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"scratch-note/config"
+	"scratch-note/notes"
+	"scratch-note/picker"
 	"scratch-note/utils"
 )
 
-// CommandType represents the type of command to execute
-type CommandType int
+// ErrNoteDiscarded is returned by CreateScratchNote when the note was deleted
+// because nothing was written to it
+var ErrNoteDiscarded = errors.New("note discarded")
 
-const (
-	CommandTypeCreate CommandType = iota
-	CommandTypeConfig
-	CommandTypeHelp
-)
+// noteNames is the filename format of notes, set from the configuration by
+// loadConfigOrExit
+var noteNames = utils.DefaultFileNameFormat()
 
-// Command represents a parsed command
-type Command struct {
-	Type  CommandType
-	Title string
-}
+// configOverrides are the configuration values given with the global flags
+var configOverrides []config.Override
 
-// EditorLauncher interface for launching editors
-type EditorLauncher interface {
-	Launch(filePath string) error
+// NoteOptions controls how CreateScratchNote creates a note
+type NoteOptions struct {
+	// Content is written to the note before the editor is launched
+	Content []byte
+	// DiscardEmpty deletes the note when it is still empty, or unchanged from
+	// its initial content, after the editor exits
+	DiscardEmpty bool
 }
 
-// RealEditor implements EditorLauncher for real editor execution
-type RealEditor struct {
-	EditorName string
-}
+// CreateScratchNote creates a new scratch note file, writes opts.Content to it
+// and opens it in editor. If opts.DiscardEmpty is set and the note is left empty, the file is removed
+// and the returned error is ErrNoteDiscarded.
+func CreateScratchNote(title, directory string, t time.Time, editor EditorLauncher, opts NoteOptions) (string, error) {
+	// Check if directory exists
+	if _, err := os.Stat(directory); os.IsNotExist(err) {
+		return "", fmt.Errorf("scratch-note directory does not exist: %s", directory)
+	}
 
-func (r *RealEditor) Launch(filePath string) error {
-	cmd := exec.Command(r.EditorName, filePath)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	
-	err := cmd.Run()
+	// Create file without clobbering an existing note
+	filePath, err := createNoteFile(directory, noteNames.NewName(title, t), opts.Content)
 	if err != nil {
-		return &EditorError{Editor: r.EditorName, Err: err.Error()}
+		return "", err
 	}
-	return nil
-}
 
-// EditorError represents an error when launching the editor
-type EditorError struct {
-	Editor string
-	Err    string
-}
-
-func (e *EditorError) Error() string {
-	return fmt.Sprintf("Editor '%s' not found: %s", e.Editor, e.Err)
-}
-
-// ParseArgs parses command line arguments
-func ParseArgs(args []string) (Command, error) {
-	if len(args) == 1 {
-		return Command{Type: CommandTypeCreate, Title: ""}, nil
+	// Launch editor
+	err = editor.Launch(filePath)
+	if err != nil {
+		return "", err
 	}
 
-	if len(args) == 2 {
-		switch args[1] {
-		case "--config":
-			return Command{Type: CommandTypeConfig}, nil
-		case "--help", "-h":
-			return Command{Type: CommandTypeHelp}, nil
-		default:
-			// Check if it's an unknown flag
-			if args[1][0] == '-' {
-				return Command{}, fmt.Errorf("unknown flag: %s", args[1])
-			}
-			return Command{Type: CommandTypeCreate, Title: args[1]}, nil
+	if opts.DiscardEmpty {
+		discarded, err := discardIfUnchanged(filePath, opts.Content)
+		if err != nil {
+			return "", err
+		}
+		if discarded {
+			return filePath, ErrNoteDiscarded
 		}
 	}
 
-	if len(args) > 2 {
-		return Command{}, fmt.Errorf("too many arguments")
-	}
-
-	return Command{}, fmt.Errorf("unknown command")
+	return filePath, nil
 }
 
-// CreateScratchNote creates a new scratch note file and opens it in editor
-func CreateScratchNote(title, directory string, t time.Time, editor EditorLauncher) (string, error) {
-	// Check if directory exists
-	if _, err := os.Stat(directory); os.IsNotExist(err) {
-		return "", fmt.Errorf("scratch-note directory does not exist: %s", directory)
-	}
-
-	// Generate filename
-	filename := utils.GenerateFileName(title, t)
-	filePath := filepath.Join(directory, filename)
-
-	// Create empty file
-	err := os.WriteFile(filePath, []byte(""), 0644)
+// discardIfUnchanged removes the note at filePath if its content is blank or
+// identical to initial, ignoring surrounding whitespace
+func discardIfUnchanged(filePath string, initial []byte) (bool, error) {
+	content, err := os.ReadFile(filePath)
 	if err != nil {
-		return "", fmt.Errorf("failed to create file: %v", err)
+		if os.IsNotExist(err) {
+			// The editor moved or deleted the note itself
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read note: %v", err)
 	}
 
-	// Launch editor
-	err = editor.Launch(filePath)
-	if err != nil {
-		return "", err
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && !bytes.Equal(trimmed, bytes.TrimSpace(initial)) {
+		return false, nil
 	}
 
-	return filePath, nil
+	if err := os.Remove(filePath); err != nil {
+		return false, fmt.Errorf("failed to discard empty note: %v", err)
+	}
+	return true, nil
 }
 
 func main() {
@@ -121,34 +102,103 @@ func main() {
 		os.Exit(1)
 	}
 
+	configOverrides = cmd.Overrides
+
 	switch cmd.Type {
 	case CommandTypeHelp:
-		printUsage()
+		printHelp(cmd.Topic)
 	case CommandTypeConfig:
-		handleConfigCommand()
+		handleConfigCommand(cmd.Config)
 	case CommandTypeCreate:
-		handleCreateCommand(cmd.Title)
+		handleCreateCommand(cmd.Title, cmd.Create)
+	case CommandTypeList:
+		handleListCommand(cmd.List)
+	case CommandTypeSearch:
+		handleSearchCommand(cmd.Search)
+	case CommandTypeIndex:
+		handleIndexCommand(cmd.Index)
+	case CommandTypeOpen, CommandTypeLast:
+		handleOpenCommand(cmd.Open)
+	case CommandTypePick:
+		handlePickCommand(cmd.Pick)
+	case CommandTypeDaily:
+		handleDailyCommand(cmd.Daily)
+	case CommandTypeAppend:
+		handleAppendCommand(cmd.Append)
+	case CommandTypeInit:
+		handleInitCommand(cmd.Init)
+	case CommandTypeMigrate:
+		handleMigrateCommand(cmd.Migrate)
+	case CommandTypeNotebooks:
+		handleNotebooksCommand()
+	case CommandTypeTag:
+		handleTagCommand(cmd.Tag)
+	case CommandTypeTags:
+		handleTagsCommand()
+	case CommandTypeDelete:
+		handleDeleteCommand(cmd.Delete)
+	case CommandTypeTrash:
+		handleTrashCommand(cmd.Trash)
+	case CommandTypeRename:
+		handleRenameCommand(cmd.Rename)
+	case CommandTypeBacklinks:
+		handleBacklinksCommand(cmd.Backlinks)
+	case CommandTypeLinks:
+		handleLinksCommand(cmd.Links)
 	}
 }
 
 func printUsage() {
-	fmt.Println("scratch-note - A simple terminal-based note-taking tool")
-	fmt.Println("")
-	fmt.Println("USAGE:")
-	fmt.Println("  scratch-note                    Create new timestamped note")
-	fmt.Println("  scratch-note \"title\"            Create note with custom title")
-	fmt.Println("  scratch-note --config           Edit configuration file")
-	fmt.Println("  scratch-note --help             Show this help message")
-	fmt.Println("")
-	fmt.Println("EXAMPLES:")
-	fmt.Println("  scratch-note                    # Creates: 2025-08-16_143045.md")
-	fmt.Println("  scratch-note \"meeting notes\"    # Creates: 2025-08-16_143045_meeting-notes.md")
-	fmt.Println("")
-	fmt.Println("CONFIGURATION:")
-	fmt.Println("  Config file: ~/.config/scratch-note/config.yaml")
-	fmt.Println("  Run 'scratch-note --config' to create or edit configuration")
-	fmt.Println("")
-	fmt.Println("For more information, visit: https://github.com/your-repo/scratch-note")
+	writeUsage(os.Stdout)
+}
+
+// printHelp prints the usage of topic, or the general usage if topic is empty
+func printHelp(topic string) {
+	if sc, ok := lookupSubcommand(topic); ok && topic != "" {
+		writeCommandUsage(os.Stdout, sc)
+		return
+	}
+	printUsage()
+}
+
+// maxNoteSeq bounds the sequence suffixes tried when a note name is taken
+const maxNoteSeq = 1000
+
+// createNoteFile exclusively creates a note file in directory holding content.
+// If a file with the same name already exists, a sequence suffix (-2, -3, ...)
+// is added to the name until a free one is found. Subdirectories named by the
+// filename format are created as needed. It returns the created path.
+func createNoteFile(directory string, name utils.NoteName, content []byte) (string, error) {
+	requested := noteNames.Format(name)
+	if err := os.MkdirAll(filepath.Dir(filepath.Join(directory, filepath.FromSlash(requested))), 0755); err != nil {
+		return "", fmt.Errorf("failed to create directory: %v", err)
+	}
+	for seq := 1; seq <= maxNoteSeq; seq++ {
+		if seq > 1 {
+			name.Seq = seq
+		}
+		filePath := filepath.Join(directory, filepath.FromSlash(noteNames.Format(name)))
+
+		file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to create file: %v", err)
+		}
+
+		_, err = file.Write(content)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(filePath)
+			return "", fmt.Errorf("failed to write file: %v", err)
+		}
+		return filePath, nil
+	}
+
+	return "", fmt.Errorf("failed to create file: too many notes named %s", requested)
 }
 
 // getConfigPath returns the path to the configuration file
@@ -161,9 +211,63 @@ func getConfigPath() string {
 	return filepath.Join(homeDir, ".config", "scratch-note", "config.yaml")
 }
 
-func handleConfigCommand() {
+// configLayers returns where the configuration is read from: the system and
+// user config files, the project config file above the current directory,
+// the environment and the global flags
+func configLayers() config.Layers {
+	layers := config.Layers{
+		SystemPath: config.SystemConfigPath,
+		UserPath:   getConfigPath(),
+		Environ:    os.Environ(),
+		Overrides:  configOverrides,
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		layers.ProjectPath = config.FindProjectConfig(cwd)
+	}
+	return layers
+}
+
+// hasConfigFile reports whether any of the config files of layers exists
+func hasConfigFile(layers config.Layers) bool {
+	for _, path := range []string{layers.SystemPath, layers.UserPath, layers.ProjectPath} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// getIndexPath returns the search index file for notesDir. Each notes
+// directory gets its own index under the config directory.
+func getIndexPath(notesDir string) string {
+	if abs, err := filepath.Abs(notesDir); err == nil {
+		notesDir = abs
+	}
+	sum := sha256.Sum256([]byte(notesDir))
+	name := fmt.Sprintf("%x.gob", sum[:8])
+	return filepath.Join(filepath.Dir(getConfigPath()), "index", name)
+}
+
+// getTemplatesDir returns the directory holding note templates, next to the config file
+func getTemplatesDir() string {
+	return filepath.Join(filepath.Dir(getConfigPath()), "templates")
+}
+
+func handleConfigCommand(opts ConfigOptions) {
 	configPath := getConfigPath()
-	
+
+	switch opts.Action {
+	case "validate":
+		handleConfigValidate()
+		return
+	case "show":
+		handleConfigShow(opts)
+		return
+	}
+
 	// Check if config file exists, create if not
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		fmt.Printf("Config file not found. Creating default config at: %s\n", configPath)
@@ -174,22 +278,16 @@ func handleConfigCommand() {
 		}
 		fmt.Println("Default config file created successfully.")
 	}
-	
-	// Load config to get editor
-	cfg, err := config.LoadConfig(configPath)
+
+	// Load config to get editor; a config with problems still has to be editable
+	src, _, err := config.ParseConfig(configPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: Failed to load config file: %v\n", err)
 		os.Exit(1)
 	}
-	
-	// Use configured editor or default to vi
-	editorName := cfg.Editor
-	if editorName == "" {
-		editorName = "vi"
-	}
-	
+
 	// Launch editor to edit config
-	editor := &RealEditor{EditorName: editorName}
+	editor := &RealEditor{EditorName: ResolveEditor(src.Config.Editor)}
 	err = editor.Launch(configPath)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -197,43 +295,397 @@ func handleConfigCommand() {
 	}
 }
 
-func handleCreateCommand(title string) {
+// handleConfigValidate prints every problem of the configuration, exiting
+// with an error status if there are any
+func handleConfigValidate() {
+	count, err := CheckConfig(configLayers(), getTemplatesDir(), os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if count > 0 {
+		fmt.Fprintf(os.Stderr, "%d problems found in the configuration\n", count)
+		os.Exit(1)
+	}
+	fmt.Println("Configuration is valid")
+}
+
+// handleConfigShow prints the effective configuration
+func handleConfigShow(opts ConfigOptions) {
+	src, problems, err := config.LoadLayers(configLayers())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	// Show what can be loaded; validate explains the rest
+	for _, p := range problems {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", p)
+	}
+	if err := ShowConfig(src, opts.Origin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadConfigOrExit loads the configuration layers, running the setup wizard
+// first if there is no config file and a terminal is attached, and exits if
+// the configuration is missing or invalid
+func loadConfigOrExit() *config.Config {
 	configPath := getConfigPath()
-	
-	// Load config or use defaults
-	var cfg *config.Config
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		// Config file doesn't exist, prompt user to create one
-		fmt.Printf("Error: Config file not found. Run 'scratch-note --config' to create one.\n")
+	layers := configLayers()
+
+	src, problems, err := config.LoadLayers(layers)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	
-	cfg, err := config.LoadConfig(configPath)
+
+	// The environment or flags can stand in for a config file
+	if !hasConfigFile(layers) && !src.Has("scratch_note_dir") {
+		// No config file exists, set one up when someone can answer
+		if !isTerminal(os.Stdin) || !isTerminal(os.Stdout) {
+			fmt.Fprintf(os.Stderr, "Error: Config file not found. Run 'scratch-note init' to create one.\n")
+			os.Exit(1)
+		}
+		fmt.Println("Config file not found. Let's set up scratch-note.")
+		if _, err := InitConfig(configPath, InitOptions{}, os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if src, problems, err = config.LoadLayers(layers); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "Error: Invalid configuration:\n%v\n", &config.ValidationError{Problems: problems})
+		os.Exit(1)
+	}
+	cfg := src.Config
+
+	noteNames, err = utils.NewFileNameFormat(cfg.FilenameTemplate, cfg.FilenameExtension, cfg.FilenameSeparator)
+	if err == nil {
+		noteNames, err = noteNames.WithLayout(cfg.Layout)
+	}
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: Invalid config file format: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	
-	// Expand path and check if directory exists
+
+	return cfg
+}
+
+// notesDirOrExit returns the expanded notes directory, creating it if it does
+// not exist and exiting if that fails
+func notesDirOrExit(cfg *config.Config) string {
+	if strings.TrimSpace(cfg.ScratchNoteDir) == "" {
+		fmt.Fprintf(os.Stderr, "Error: scratch_note_dir is not set\n")
+		os.Exit(1)
+	}
+
 	notesDir := config.ExpandPath(cfg.ScratchNoteDir)
 	if _, err := os.Stat(notesDir); os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Error: scratch-note directory does not exist: %s\n", notesDir)
-		os.Exit(1)
+		if err := os.MkdirAll(notesDir, defaultDirMode); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to create scratch-note directory: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Created scratch-note directory: %s\n", notesDir)
+	}
+	return notesDir
+}
+
+func handleCreateCommand(title string, createOpts CreateOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	// Take the content from -m or piped input, otherwise open the editor
+	var editor EditorLauncher = &RealEditor{EditorName: ResolveEditor(cfg.Editor)}
+	switch {
+	case len(createOpts.Messages) > 0:
+		editor = MessageSource(createOpts.Messages)
+	case createOpts.Stdin || !isTerminal(os.Stdin):
+		editor = &ReaderSource{Reader: os.Stdin}
 	}
-	
-	// Use configured editor or default to vi
-	editorName := cfg.Editor
-	if editorName == "" {
-		editorName = "vi"
+
+	now := time.Now()
+	opts := NoteOptions{DiscardEmpty: !cfg.KeepEmptyNotes}
+
+	content, err := initialContent(title, now, cfg, createOpts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
-	
+	opts.Content = content
+
 	// Create scratch note
-	editor := &RealEditor{EditorName: editorName}
-	filePath, err := CreateScratchNote(title, notesDir, time.Now(), editor)
+	filePath, err := CreateScratchNote(title, notesDir, now, editor, opts)
+	if errors.Is(err, ErrNoteDiscarded) {
+		fmt.Printf("Scratch-note discarded: %s was left empty\n", filePath)
+		return
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
-	
+
 	fmt.Printf("Created scratch-note: %s\n", filePath)
-}
\ No newline at end of file
+}
+
+// initialContent builds the content a new note starts with: the requested or
+// default template, preceded by frontmatter when enabled in the config or
+// when the note is tagged
+func initialContent(title string, t time.Time, cfg *config.Config, createOpts CreateOptions) ([]byte, error) {
+	data := NewTemplateData(title, t, createOpts.Vars)
+
+	var content []byte
+	templateName := createOpts.Template
+	if templateName == "" {
+		templateName = cfg.DefaultTemplate
+	}
+	if templateName != "" {
+		rendered, err := renderNamedTemplate(templateName, data)
+		if err != nil {
+			return nil, err
+		}
+		content = rendered
+	}
+
+	if cfg.Frontmatter {
+		fm := data.Frontmatter()
+		fm.Tags = createOpts.Tags
+		return notes.AddFrontmatter(content, fm)
+	}
+	if len(createOpts.Tags) > 0 {
+		// Tags are kept in frontmatter even when it is not enabled
+		return notes.AddFrontmatter(content, notes.Frontmatter{Tags: createOpts.Tags})
+	}
+	return content, nil
+}
+
+// renderNamedTemplate loads the template called name from the templates directory and renders it
+func renderNamedTemplate(name string, data TemplateData) ([]byte, error) {
+	text, err := LoadTemplate(getTemplatesDir(), name)
+	if err != nil {
+		return nil, err
+	}
+	return RenderTemplate(name, text, data)
+}
+
+func handleListCommand(opts ListOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	err := ListNotes(notesDir, opts, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleSearchCommand(opts SearchOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	opts.Color = !opts.NoColor && isTerminal(os.Stdout)
+	err := SearchNotes(notesDir, getIndexPath(notesDir), opts, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleIndexCommand(opts IndexOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	err := BuildIndex(notesDir, getIndexPath(notesDir), opts, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleOpenCommand(opts OpenOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	editor := &RealEditor{EditorName: ResolveEditor(cfg.Editor)}
+	_, err := OpenNote(notesDir, opts, editor)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handlePickCommand(opts PickOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	tty, err := picker.OpenTTY()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer tty.Close()
+
+	editor := &RealEditor{EditorName: ResolveEditor(cfg.Editor)}
+	_, err = PickNote(notesDir, opts, tty, editor, os.Stdout)
+	if errors.Is(err, picker.ErrCancelled) {
+		tty.Close()
+		os.Exit(130)
+	}
+	if err != nil {
+		tty.Close()
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleDailyCommand(opts DailyOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	editor := &RealEditor{EditorName: ResolveEditor(cfg.Editor)}
+	filePath, err := OpenDailyNote(notesDir, opts.entryTime(time.Now()), editor, !cfg.KeepEmptyNotes)
+	if errors.Is(err, ErrNoteDiscarded) {
+		fmt.Printf("Daily entry discarded: nothing was written to %s\n", filePath)
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Updated daily note: %s\n", filePath)
+}
+
+func handleAppendCommand(opts AppendOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	var input io.Reader
+	if opts.Text == "" && !isTerminal(os.Stdin) {
+		input = os.Stdin
+	}
+	filePath, err := AppendNote(notesDir, opts, input, time.Now())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Appended to: %s\n", filePath)
+}
+
+func handleMigrateCommand(opts MigrateOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	if err := MigrateNotes(notesDir, opts, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleInitCommand(opts InitOptions) {
+	if _, err := InitConfig(getConfigPath(), opts, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleNotebooksCommand() {
+	cfg := loadConfigOrExit()
+	if err := ListNotebooks(cfg, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleTagCommand(opts TagOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	path, err := TagNote(notesDir, opts, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Updated tags of: %s\n", path)
+}
+
+func handleTagsCommand() {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	if err := ListTags(notesDir, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleDeleteCommand(opts DeleteOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	if err := DeleteNotes(notesDir, opts.Refs, time.Now(), os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleTrashCommand(opts TrashOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	var err error
+	switch opts.Action {
+	case TrashActionRestore:
+		err = RestoreNotes(notesDir, opts.Refs, os.Stdout)
+	case TrashActionEmpty:
+		err = EmptyTrash(notesDir, opts.OlderThan, time.Now(), os.Stdout)
+	default:
+		err = ListTrash(notesDir, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleRenameCommand(opts RenameOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	if _, err := RenameNote(notesDir, opts, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func handleBacklinksCommand(opts BacklinksOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	if err := ListBacklinks(notesDir, opts, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// handleLinksCommand prints the link report, exiting with an error status if
+// any link is broken or ambiguous
+func handleLinksCommand(opts LinksOptions) {
+	cfg := loadConfigOrExit()
+	notesDir := notesDirOrExit(cfg)
+
+	count, err := WriteLinkReport(notesDir, opts, os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if count > 0 {
+		fmt.Fprintf(os.Stderr, "%d broken or ambiguous links found\n", count)
+		os.Exit(1)
+	}
+}
@@ -1,16 +1,22 @@
 package main
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
+
+	"scratch-note/utils"
 )
 
 // MockEditor for testing editor launching
 type MockEditor struct {
 	CalledWith []string
 	ShouldFail bool
+	// Content, if set, is appended to the file as if typed by the user
+	Content string
 }
 
 func (m *MockEditor) Launch(filePath string) error {
@@ -18,6 +24,15 @@ func (m *MockEditor) Launch(filePath string) error {
 	if m.ShouldFail {
 		return &EditorError{Editor: "mock-editor", Err: "command not found"}
 	}
+	if m.Content != "" {
+		file, err := os.OpenFile(filePath, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		_, err = file.WriteString(m.Content)
+		return err
+	}
 	return nil
 }
 
@@ -134,7 +149,7 @@ func TestCreateScratchNote(t *testing.T) {
 			}
 
 			mockEditor := &MockEditor{}
-			filePath, err := CreateScratchNote(tt.title, noteDir, time.Now(), mockEditor)
+			filePath, err := CreateScratchNote(tt.title, noteDir, time.Now(), mockEditor, NoteOptions{})
 			
 			if tt.expectError {
 				if err == nil {
@@ -186,7 +201,7 @@ func TestCreateScratchNoteEditorFailure(t *testing.T) {
 	}
 
 	mockEditor := &MockEditor{ShouldFail: true}
-	_, err = CreateScratchNote("", noteDir, time.Now(), mockEditor)
+	_, err = CreateScratchNote("", noteDir, time.Now(), mockEditor, NoteOptions{})
 	
 	if err == nil {
 		t.Error("Expected error when editor fails")
@@ -202,4 +217,163 @@ func TestCreateScratchNoteEditorFailure(t *testing.T) {
 func containsTitle(filename, title string) bool {
 	// Simple check - in real implementation this would be more sophisticated
 	return len(filename) > 20 // Basic timestamp is 20 chars, so longer means title was added
-}
\ No newline at end of file
+}
+func TestCreateScratchNoteDoesNotClobberExistingNote(t *testing.T) {
+	noteDir := t.TempDir()
+	testTime := time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local)
+
+	existing := filepath.Join(noteDir, "2025-08-16_143045_standup.md")
+	err := os.WriteFile(existing, []byte("keep me"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write existing note: %v", err)
+	}
+
+	filePath, err := CreateScratchNote("standup", noteDir, testTime, &MockEditor{}, NoteOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := filepath.Join(noteDir, "2025-08-16_143045-2_standup.md")
+	if filePath != expected {
+		t.Errorf("CreateScratchNote returned %q, want %q", filePath, expected)
+	}
+
+	content, err := os.ReadFile(existing)
+	if err != nil {
+		t.Fatalf("Failed to read existing note: %v", err)
+	}
+	if string(content) != "keep me" {
+		t.Errorf("Existing note was modified: %q", content)
+	}
+}
+
+// useNoteNames switches to the filename format given by template, ext and
+// separator for the rest of the test
+func useNoteNames(t *testing.T, template, ext, separator string) {
+	t.Helper()
+	format, err := utils.NewFileNameFormat(template, ext, separator)
+	if err != nil {
+		t.Fatalf("NewFileNameFormat() unexpected error: %v", err)
+	}
+	previous := noteNames
+	noteNames = format
+	t.Cleanup(func() { noteNames = previous })
+}
+
+func TestCreateScratchNoteCustomFormat(t *testing.T) {
+	useNoteNames(t, "{date:2006-01-02}/{time:150405}-{slug}", ".txt", "_")
+	noteDir := t.TempDir()
+	testTime := time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local)
+
+	for _, expected := range []string{"2025-08-16/143045-team_sync.txt", "2025-08-16/143045-2-team_sync.txt"} {
+		filePath, err := CreateScratchNote("team sync", noteDir, testTime, &MockEditor{}, NoteOptions{})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if filePath != filepath.Join(noteDir, filepath.FromSlash(expected)) {
+			t.Errorf("CreateScratchNote returned %q, want %s", filePath, expected)
+		}
+	}
+}
+
+func TestCreateScratchNoteConcurrent(t *testing.T) {
+	noteDir := t.TempDir()
+	testTime := time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local)
+
+	const workers = 20
+	var wg sync.WaitGroup
+	paths := make([]string, workers)
+	errs := make([]error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			paths[i], errs[i] = CreateScratchNote("same title", noteDir, testTime, &MockEditor{}, NoteOptions{})
+		}(i)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for i := 0; i < workers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("Worker %d failed: %v", i, errs[i])
+		}
+		if seen[paths[i]] {
+			t.Errorf("Path %s was returned more than once", paths[i])
+		}
+		seen[paths[i]] = true
+	}
+
+	entries, err := os.ReadDir(noteDir)
+	if err != nil {
+		t.Fatalf("Failed to read note directory: %v", err)
+	}
+	if len(entries) != workers {
+		t.Errorf("Expected %d notes, found %d", workers, len(entries))
+	}
+}
+
+func TestCreateScratchNoteDiscardEmpty(t *testing.T) {
+	tests := []struct {
+		name              string
+		content           string
+		discardEmpty      bool
+		expectedDiscarded bool
+	}{
+		{
+			name:              "empty note discarded",
+			content:           "",
+			discardEmpty:      true,
+			expectedDiscarded: true,
+		},
+		{
+			name:              "whitespace-only note discarded",
+			content:           "\n  \n",
+			discardEmpty:      true,
+			expectedDiscarded: true,
+		},
+		{
+			name:              "note with content kept",
+			content:           "remember the milk\n",
+			discardEmpty:      true,
+			expectedDiscarded: false,
+		},
+		{
+			name:              "empty note kept when discarding is off",
+			content:           "",
+			discardEmpty:      false,
+			expectedDiscarded: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			noteDir := t.TempDir()
+			mockEditor := &MockEditor{Content: tt.content}
+
+			filePath, err := CreateScratchNote("note", noteDir, time.Now(), mockEditor, NoteOptions{DiscardEmpty: tt.discardEmpty})
+
+			if tt.expectedDiscarded {
+				if !errors.Is(err, ErrNoteDiscarded) {
+					t.Fatalf("Expected ErrNoteDiscarded, got %v", err)
+				}
+				if _, statErr := os.Stat(filePath); !os.IsNotExist(statErr) {
+					t.Errorf("Discarded note should be removed: %s", filePath)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			content, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatalf("Note should be kept: %v", err)
+			}
+			if string(content) != tt.content {
+				t.Errorf("Note content = %q, want %q", content, tt.content)
+			}
+		})
+	}
+}
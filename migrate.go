@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"scratch-note/utils"
+)
+
+// MigrateOptions holds the options of the migrate command
+type MigrateOptions struct {
+	// DryRun reports the moves without making them
+	DryRun bool
+}
+
+// MigrateNotes moves every note in directory that is not where the configured
+// layout puts it, e.g. flat notes into 2025/08/ for the monthly layout, and
+// reports each move to w. Directories left empty are removed. A note whose
+// name is taken at the destination gets a sequence suffix; for daily notes,
+// which cannot have one, this is an error.
+func MigrateNotes(directory string, opts MigrateOptions, w io.Writer) error {
+	notes, err := collectNotes(directory)
+	if err != nil {
+		return err
+	}
+	sortNotes(notes, SortCreated, true)
+
+	moved := 0
+	for _, note := range notes {
+		target, err := migrationTarget(directory, note, opts.DryRun)
+		if err != nil {
+			return err
+		}
+		if target == note.Path {
+			continue
+		}
+
+		from, _ := filepath.Rel(directory, note.Path)
+		to, _ := filepath.Rel(directory, target)
+		moved++
+		if opts.DryRun {
+			fmt.Fprintf(w, "Would move %s -> %s\n", from, to)
+			continue
+		}
+
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %v", err)
+		}
+		if err := os.Rename(note.Path, target); err != nil {
+			return fmt.Errorf("failed to move %s: %v", from, err)
+		}
+		removeEmptyDirs(directory, filepath.Dir(note.Path))
+		fmt.Fprintf(w, "Moved %s -> %s\n", from, to)
+	}
+
+	switch {
+	case moved == 0:
+		fmt.Fprintf(w, "All notes already follow the %s layout\n", noteNames.Layout)
+	case opts.DryRun:
+		fmt.Fprintf(w, "%d notes would be moved to the %s layout\n", moved, noteNames.Layout)
+	default:
+		fmt.Fprintf(w, "Moved %d notes to the %s layout\n", moved, noteNames.Layout)
+	}
+	return nil
+}
+
+// migrationTarget returns the path note belongs at in the configured layout.
+// It is note.Path itself when the note is already in place, otherwise a free
+// path. With dryRun, taken paths are not avoided.
+func migrationTarget(directory string, note NoteEntry, dryRun bool) (string, error) {
+	if note.Daily {
+		target := filepath.Join(directory, filepath.FromSlash(noteNames.DailyFileName(note.Created)))
+		if target == note.Path || dryRun {
+			return target, nil
+		}
+		if _, err := os.Lstat(target); err == nil {
+			return "", fmt.Errorf("cannot move %s: %s already exists", note.Path, target)
+		}
+		return target, nil
+	}
+
+	name := utils.NoteName{Time: note.Created, Slug: note.Title, Ext: noteNames.Ext, Seq: note.Seq}
+	target := filepath.Join(directory, filepath.FromSlash(noteNames.Format(name)))
+	if target == note.Path || dryRun {
+		return target, nil
+	}
+	for seq := max(name.Seq, 1) + 1; seq <= maxNoteSeq; seq++ {
+		if _, err := os.Lstat(target); os.IsNotExist(err) {
+			return target, nil
+		}
+		name.Seq = seq
+		target = filepath.Join(directory, filepath.FromSlash(noteNames.Format(name)))
+	}
+	return "", fmt.Errorf("cannot move %s: too many notes with its name", note.Path)
+}
+
+// removeEmptyDirs removes dir and its parents up to, but not including,
+// directory for as long as they are empty
+func removeEmptyDirs(directory, dir string) {
+	for dir != directory && len(dir) > len(directory) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
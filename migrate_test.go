@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useLayout switches the filename format to layout for the rest of the test
+func useLayout(t *testing.T, layout string) {
+	t.Helper()
+	format, err := noteNames.WithLayout(layout)
+	if err != nil {
+		t.Fatalf("WithLayout() unexpected error: %v", err)
+	}
+	previous := noteNames
+	noteNames = format
+	t.Cleanup(func() { noteNames = previous })
+}
+
+// listRelative returns the files under dir relative to it, with "/" separators
+func listRelative(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to walk %s: %v", dir, err)
+	}
+	return files
+}
+
+func TestCreateScratchNoteLayout(t *testing.T) {
+	useLayout(t, "monthly")
+	noteDir := t.TempDir()
+
+	filePath, err := CreateScratchNote("standup", noteDir, time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local), &MockEditor{}, NoteOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := filepath.Join(noteDir, "2025", "08", "2025-08-16_143045_standup.md"); filePath != expected {
+		t.Errorf("CreateScratchNote returned %q, want %q", filePath, expected)
+	}
+
+	if _, err := LastNote(noteDir, 1); err != nil {
+		t.Errorf("LastNote() should find the note in its month directory: %v", err)
+	}
+}
+
+func TestMigrateNotes(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-16_143045_standup.md",
+		"2025-08-16.md",
+		"2024/12/2024-12-31_235959.md",
+		"2025/2025-08-17_090000_retro.md",
+		"2025/2025-08-16_143045_standup.md",
+		"README.md",
+	)
+	useLayout(t, "monthly")
+
+	var buf bytes.Buffer
+	if err := MigrateNotes(dir, MigrateOptions{DryRun: true}, &buf); err != nil {
+		t.Fatalf("MigrateNotes() dry run unexpected error: %v", err)
+	}
+	if !strings.Contains(buf.String(), "4 notes would be moved") {
+		t.Errorf("Dry run output = %q", buf.String())
+	}
+	if files := listRelative(t, dir); len(files) != 6 || files[0] != "2024/12/2024-12-31_235959.md" {
+		t.Errorf("Dry run moved notes: %v", files)
+	}
+
+	buf.Reset()
+	if err := MigrateNotes(dir, MigrateOptions{}, &buf); err != nil {
+		t.Fatalf("MigrateNotes() unexpected error: %v", err)
+	}
+
+	expected := []string{
+		"2024/12/2024-12-31_235959.md",
+		"2025/08/2025-08-16.md",
+		"2025/08/2025-08-16_143045-2_standup.md",
+		"2025/08/2025-08-16_143045_standup.md",
+		"2025/08/2025-08-17_090000_retro.md",
+		"README.md",
+	}
+	if files := listRelative(t, dir); strings.Join(files, " ") != strings.Join(expected, " ") {
+		t.Errorf("Notes after migration = %v, want %v", files, expected)
+	}
+
+	// Migrating back removes the emptied directories
+	useLayout(t, "flat")
+	buf.Reset()
+	if err := MigrateNotes(dir, MigrateOptions{}, &buf); err != nil {
+		t.Fatalf("MigrateNotes() unexpected error: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025")); !os.IsNotExist(err) {
+		t.Errorf("Empty directory 2025 should be removed, got %v", err)
+	}
+	if files := listRelative(t, dir); len(files) != 6 {
+		t.Errorf("Notes after migrating back = %v", files)
+	}
+}
+
+func TestMigrateNotesCustomFormat(t *testing.T) {
+	useNoteNames(t, "{date:2006-01-02}/{time:150405}-{slug}", ".org", "")
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-16_143045_standup.md",
+		"2025-08-17.md",
+		"2025-08-18/090000-retro.org",
+	)
+
+	if err := MigrateNotes(dir, MigrateOptions{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("MigrateNotes() unexpected error: %v", err)
+	}
+
+	// Notes in the default format are renamed into the configured one
+	expected := []string{"2025-08-16/143045-standup.org", "2025-08-17.org", "2025-08-18/090000-retro.org"}
+	if files := listRelative(t, dir); strings.Join(files, " ") != strings.Join(expected, " ") {
+		t.Errorf("Notes after migration = %v, want %v", files, expected)
+	}
+}
+
+func TestMigrateNotesDailyConflict(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(), "2025-08-16.md", "2025/08/2025-08-16.md")
+	useLayout(t, "monthly")
+
+	if err := MigrateNotes(dir, MigrateOptions{}, &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for two daily notes of the same day")
+	}
+}
@@ -0,0 +1,207 @@
+package notes
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Frontmatter holds the metadata scratch-note keeps in a note's YAML frontmatter
+type Frontmatter struct {
+	Title   string    `yaml:"title,omitempty"`
+	Created time.Time `yaml:"created,omitempty"`
+	Tags    []string  `yaml:"tags,omitempty"`
+	Cwd     string    `yaml:"cwd,omitempty"`
+	Project string    `yaml:"project,omitempty"`
+}
+
+// Document is a note split into its frontmatter and markdown body. The body
+// is kept byte for byte; only the frontmatter is re-encoded on changes.
+type Document struct {
+	// meta is the frontmatter mapping node, nil when the note has none
+	meta *yaml.Node
+	Body []byte
+}
+
+// Parse splits content into frontmatter and body. Content without a
+// frontmatter block yields a Document with the whole content as body.
+func Parse(content []byte) (*Document, error) {
+	front, body, ok := split(content)
+	if !ok {
+		return &Document{Body: content}, nil
+	}
+
+	meta := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	if len(bytes.TrimSpace(front)) > 0 {
+		var root yaml.Node
+		if err := yaml.Unmarshal(front, &root); err != nil {
+			return nil, fmt.Errorf("invalid frontmatter: %v", err)
+		}
+		if len(root.Content) != 1 || root.Content[0].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("invalid frontmatter: expected a mapping")
+		}
+		meta = root.Content[0]
+	}
+
+	return &Document{meta: meta, Body: body}, nil
+}
+
+// HasFrontmatter reports whether the document has a frontmatter block
+func (d *Document) HasFrontmatter() bool {
+	return d.meta != nil
+}
+
+// Frontmatter decodes the known frontmatter fields
+func (d *Document) Frontmatter() (Frontmatter, error) {
+	var fm Frontmatter
+	if d.meta == nil {
+		return fm, nil
+	}
+	if err := d.meta.Decode(&fm); err != nil {
+		return Frontmatter{}, fmt.Errorf("invalid frontmatter: %v", err)
+	}
+	return fm, nil
+}
+
+// Has reports whether the frontmatter contains key
+func (d *Document) Has(key string) bool {
+	return d.index(key) >= 0
+}
+
+// Set stores value under key, replacing an existing value and keeping the
+// position of the key. A frontmatter block is added if the note has none.
+func (d *Document) Set(key string, value interface{}) error {
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("failed to encode frontmatter %s: %v", key, err)
+	}
+
+	if d.meta == nil {
+		d.meta = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	if i := d.index(key); i >= 0 {
+		d.meta.Content[i+1] = &node
+		return nil
+	}
+
+	keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+	d.meta.Content = append(d.meta.Content, keyNode, &node)
+	return nil
+}
+
+// Delete removes key from the frontmatter, and the frontmatter block itself
+// when no keys remain
+func (d *Document) Delete(key string) {
+	if i := d.index(key); i >= 0 {
+		d.meta.Content = append(d.meta.Content[:i], d.meta.Content[i+2:]...)
+		if len(d.meta.Content) == 0 {
+			d.meta = nil
+		}
+	}
+}
+
+// Bytes renders the document back into note content
+func (d *Document) Bytes() ([]byte, error) {
+	if d.meta == nil {
+		return d.Body, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	if len(d.meta.Content) > 0 {
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(d.meta); err != nil {
+			return nil, fmt.Errorf("failed to encode frontmatter: %v", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, fmt.Errorf("failed to encode frontmatter: %v", err)
+		}
+	}
+	buf.WriteString("---\n")
+	buf.Write(d.Body)
+	return buf.Bytes(), nil
+}
+
+// index returns the position of key in the frontmatter mapping, or -1
+func (d *Document) index(key string) int {
+	if d.meta == nil {
+		return -1
+	}
+	for i := 0; i+1 < len(d.meta.Content); i += 2 {
+		if d.meta.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// AddFrontmatter adds the non-empty fields of fm to the frontmatter of
+// content. Keys content already defines are left as they are.
+func AddFrontmatter(content []byte, fm Frontmatter) ([]byte, error) {
+	doc, err := Parse(content)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []struct {
+		key   string
+		value interface{}
+		empty bool
+	}{
+		{"title", fm.Title, fm.Title == ""},
+		{"created", fm.Created, fm.Created.IsZero()},
+		{"tags", fm.Tags, len(fm.Tags) == 0},
+		{"cwd", fm.Cwd, fm.Cwd == ""},
+		{"project", fm.Project, fm.Project == ""},
+	}
+
+	if !doc.HasFrontmatter() {
+		// Always start a block so the note is recognisable as having metadata
+		doc.meta = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	for _, field := range fields {
+		if field.empty || doc.Has(field.key) {
+			continue
+		}
+		if err := doc.Set(field.key, field.value); err != nil {
+			return nil, err
+		}
+	}
+
+	return doc.Bytes()
+}
+
+// split separates a leading "---" delimited frontmatter block from the body
+func split(content []byte) (front, body []byte, ok bool) {
+	first, rest, found := cutLine(content)
+	if !found || string(first) != "---" {
+		return nil, nil, false
+	}
+
+	offset := 0
+	for {
+		line, next, found := cutLine(rest[offset:])
+		if string(line) == "---" || string(line) == "..." {
+			end := len(rest) - len(next)
+			return rest[:offset], rest[end:], true
+		}
+		if !found {
+			return nil, nil, false
+		}
+		offset = len(rest) - len(next)
+	}
+}
+
+// cutLine returns the first line of b without its line ending, the rest of b
+// after it, and whether a line ending was found
+func cutLine(b []byte) (line, rest []byte, found bool) {
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return bytes.TrimSuffix(b, []byte("\r")), nil, false
+	}
+	return bytes.TrimSuffix(b[:i], []byte("\r")), b[i+1:], true
+}
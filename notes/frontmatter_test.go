@@ -0,0 +1,222 @@
+package notes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name                string
+		content             string
+		expectedFrontmatter Frontmatter
+		expectedBody        string
+		expectedHas         bool
+		expectError         bool
+	}{
+		{
+			name:         "no frontmatter",
+			content:      "# Title\n\nbody\n",
+			expectedBody: "# Title\n\nbody\n",
+		},
+		{
+			name:    "with frontmatter",
+			content: "---\ntitle: Meeting notes\ncreated: 2025-08-16T14:30:45Z\ntags: [infra, bug]\n---\n# Body\n",
+			expectedFrontmatter: Frontmatter{
+				Title:   "Meeting notes",
+				Created: time.Date(2025, 8, 16, 14, 30, 45, 0, time.UTC),
+				Tags:    []string{"infra", "bug"},
+			},
+			expectedBody: "# Body\n",
+			expectedHas:  true,
+		},
+		{
+			name:         "empty frontmatter",
+			content:      "---\n---\nbody",
+			expectedBody: "body",
+			expectedHas:  true,
+		},
+		{
+			name:                "windows line endings",
+			content:             "---\r\ntitle: x\r\n---\r\nbody\r\n",
+			expectedFrontmatter: Frontmatter{Title: "x"},
+			expectedBody:        "body\r\n",
+			expectedHas:         true,
+		},
+		{
+			name:                "closing delimiter at end of file",
+			content:             "---\ntitle: x\n---",
+			expectedFrontmatter: Frontmatter{Title: "x"},
+			expectedBody:        "",
+			expectedHas:         true,
+		},
+		{
+			name:         "unterminated frontmatter is body",
+			content:      "---\ntitle: x\nno end",
+			expectedBody: "---\ntitle: x\nno end",
+		},
+		{
+			name:         "horizontal rule later in body",
+			content:      "text\n---\nmore\n",
+			expectedBody: "text\n---\nmore\n",
+		},
+		{
+			name:        "frontmatter that is not a mapping",
+			content:     "---\n- a\n- b\n---\nbody",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.content))
+
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if doc.HasFrontmatter() != tt.expectedHas {
+				t.Errorf("HasFrontmatter() = %v, want %v", doc.HasFrontmatter(), tt.expectedHas)
+			}
+
+			if string(doc.Body) != tt.expectedBody {
+				t.Errorf("Body = %q, want %q", doc.Body, tt.expectedBody)
+			}
+
+			fm, err := doc.Frontmatter()
+			if err != nil {
+				t.Fatalf("Frontmatter() unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(fm, tt.expectedFrontmatter) {
+				t.Errorf("Frontmatter() = %+v, want %+v", fm, tt.expectedFrontmatter)
+			}
+		})
+	}
+}
+
+func TestDocumentSetKeepsBodyAndUnknownKeys(t *testing.T) {
+	body := "# Heading\n\n  indented *markdown*\n---\ntrailing\n"
+	content := "---\ntitle: Old\ncustom: keep me\n---\n" + body
+
+	doc, err := Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := doc.Set("title", "New title"); err != nil {
+		t.Fatalf("Set title failed: %v", err)
+	}
+	if err := doc.Set("tags", []string{"a", "b"}); err != nil {
+		t.Fatalf("Set tags failed: %v", err)
+	}
+
+	out, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+
+	if !strings.HasSuffix(string(out), "---\n"+body) {
+		t.Errorf("Body should be untouched, got:\n%s", out)
+	}
+
+	reparsed, err := Parse(out)
+	if err != nil {
+		t.Fatalf("Failed to reparse: %v", err)
+	}
+	fm, err := reparsed.Frontmatter()
+	if err != nil {
+		t.Fatalf("Frontmatter() unexpected error: %v", err)
+	}
+	if fm.Title != "New title" || !reflect.DeepEqual(fm.Tags, []string{"a", "b"}) {
+		t.Errorf("Unexpected frontmatter after update: %+v", fm)
+	}
+	if !strings.Contains(string(out), "custom: keep me") {
+		t.Errorf("Unknown keys should be preserved, got:\n%s", out)
+	}
+
+	// Updated keys keep their position
+	if strings.Index(string(out), "title:") > strings.Index(string(out), "custom:") {
+		t.Errorf("title should stay before custom, got:\n%s", out)
+	}
+
+	reparsed.Delete("custom")
+	out, err = reparsed.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	if strings.Contains(string(out), "custom") {
+		t.Errorf("Deleted key should be gone, got:\n%s", out)
+	}
+}
+
+func TestDocumentSetAddsFrontmatter(t *testing.T) {
+	doc, err := Parse([]byte("plain body\n"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if err := doc.Set("title", "Added"); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+
+	out, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	if string(out) != "---\ntitle: Added\n---\nplain body\n" {
+		t.Errorf("Bytes() = %q", out)
+	}
+}
+
+func TestAddFrontmatter(t *testing.T) {
+	created := time.Date(2025, 8, 16, 14, 30, 45, 0, time.UTC)
+	fm := Frontmatter{Title: "standup", Created: created, Cwd: "/src/app", Project: "app"}
+
+	t.Run("empty note", func(t *testing.T) {
+		out, err := AddFrontmatter(nil, fm)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		expected := "---\ntitle: standup\ncreated: 2025-08-16T14:30:45Z\ncwd: /src/app\nproject: app\n---\n"
+		if string(out) != expected {
+			t.Errorf("AddFrontmatter() = %q, want %q", out, expected)
+		}
+	})
+
+	t.Run("template with its own frontmatter", func(t *testing.T) {
+		template := "---\ntitle: Weekly sync\ntags: [meeting]\n---\n# Agenda\n"
+		out, err := AddFrontmatter([]byte(template), fm)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		doc, err := Parse(out)
+		if err != nil {
+			t.Fatalf("Failed to parse result: %v", err)
+		}
+		got, err := doc.Frontmatter()
+		if err != nil {
+			t.Fatalf("Frontmatter() unexpected error: %v", err)
+		}
+
+		if got.Title != "Weekly sync" {
+			t.Errorf("Template title should win, got %q", got.Title)
+		}
+		if !reflect.DeepEqual(got.Tags, []string{"meeting"}) || !got.Created.Equal(created) || got.Project != "app" {
+			t.Errorf("Unexpected merged frontmatter: %+v", got)
+		}
+		if string(doc.Body) != "# Agenda\n" {
+			t.Errorf("Body = %q, want template body", doc.Body)
+		}
+	})
+}
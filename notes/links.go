@@ -0,0 +1,110 @@
+package notes
+
+import (
+	"bytes"
+	"strings"
+)
+
+// WikiLink is a [[target]] link in a note. The target may be followed by
+// #heading and |alias, which are kept when the link is rewritten.
+type WikiLink struct {
+	// Target names the linked note, without heading or alias
+	Target string
+	// Start and End are the byte offsets of the link, brackets included
+	Start, End int
+	// Line is the 1-based line number of the link
+	Line int
+	// targetEnd is the offset just after Target
+	targetEnd int
+}
+
+// WikiLinks returns the [[wiki-links]] in content in order. Links in code
+// blocks and code spans are skipped.
+func WikiLinks(content []byte) []WikiLink {
+	var links []WikiLink
+	fence := ""
+	offset := 0
+	for i, line := range strings.SplitAfter(string(content), "\n") {
+		start := offset
+		offset += len(line)
+
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		links = append(links, lineWikiLinks(line, i+1, start)...)
+	}
+	return links
+}
+
+// lineWikiLinks returns the wiki-links in line number n outside code spans,
+// with offsets shifted by start
+func lineWikiLinks(line string, n, start int) []WikiLink {
+	var links []WikiLink
+	inCode := false
+	for i := 0; i < len(line); i++ {
+		if line[i] == '`' {
+			inCode = !inCode
+			continue
+		}
+		if inCode || !strings.HasPrefix(line[i:], "[[") {
+			continue
+		}
+
+		end := strings.Index(line[i+2:], "]]")
+		if end < 0 {
+			return links
+		}
+		inner := line[i+2 : i+2+end]
+		if strings.ContainsAny(inner, "[\n") {
+			continue
+		}
+		target := inner
+		if cut := strings.IndexAny(target, "#|"); cut >= 0 {
+			target = target[:cut]
+		}
+		if strings.TrimSpace(target) != "" {
+			links = append(links, WikiLink{
+				Target:    strings.TrimSpace(target),
+				Start:     start + i,
+				End:       start + i + 2 + end + 2,
+				Line:      n,
+				targetEnd: start + i + 2 + len(target),
+			})
+		}
+		i += 2 + end + 1
+	}
+	return links
+}
+
+// RewriteWikiLinks replaces the target of every wiki-link in content for
+// which rewrite returns a new target and true, keeping headings and aliases.
+// It returns the new content and whether anything changed.
+func RewriteWikiLinks(content []byte, rewrite func(target string) (string, bool)) ([]byte, bool) {
+	var out bytes.Buffer
+	changed := false
+	last := 0
+	for _, link := range WikiLinks(content) {
+		target, ok := rewrite(link.Target)
+		if !ok || target == link.Target {
+			continue
+		}
+		out.Write(content[last : link.Start+2])
+		out.WriteString(target)
+		last = link.targetEnd
+		changed = true
+	}
+	if !changed {
+		return content, false
+	}
+	out.Write(content[last:])
+	return out.Bytes(), true
+}
@@ -0,0 +1,98 @@
+package notes
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWikiLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "links in text",
+			content:  "See [[disk-full]] and [[2025-09-01_100000]].\n[[Standup notes]]\n",
+			expected: []string{"disk-full", "2025-09-01_100000", "Standup notes"},
+		},
+		{
+			name:     "heading and alias are not part of the target",
+			content:  "[[disk-full#Fix|the fix]] [[ standup | daily ]]\n",
+			expected: []string{"disk-full", "standup"},
+		},
+		{
+			name:     "code is skipped",
+			content:  "`[[nope]]`\n```\n[[nope]]\n```\n[[yes]]\n",
+			expected: []string{"yes"},
+		},
+		{
+			name:    "empty and unclosed links",
+			content: "[[]] [[#heading]] [[open\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, link := range WikiLinks([]byte(tt.content)) {
+				got = append(got, link.Target)
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("WikiLinks() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+
+	content := "one\n```\n[[code]]\n```\nsee [[a]] and [[b|B]]\n"
+	links := WikiLinks([]byte(content))
+	if len(links) != 2 || links[0].Line != 5 || links[1].Line != 5 {
+		t.Fatalf("WikiLinks() = %+v, want two links on line 5", links)
+	}
+	if text := content[links[1].Start:links[1].End]; text != "[[b|B]]" {
+		t.Errorf("Link text = %q, want %q", text, "[[b|B]]")
+	}
+}
+
+func TestRewriteWikiLinks(t *testing.T) {
+	rewrite := func(target string) (string, bool) {
+		if strings.EqualFold(target, "old") {
+			return "new", true
+		}
+		return "", false
+	}
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+		changed  bool
+	}{
+		{
+			name:     "heading and alias kept",
+			content:  "a [[old]] b [[Old#Fix|fix]] c [[other]]\n",
+			expected: "a [[new]] b [[new#Fix|fix]] c [[other]]\n",
+			changed:  true,
+		},
+		{
+			name:     "code untouched",
+			content:  "`[[old]]`\n```\n[[old]]\n```\n",
+			expected: "`[[old]]`\n```\n[[old]]\n```\n",
+		},
+		{
+			name:     "nothing to rewrite",
+			content:  "[[other]]\n",
+			expected: "[[other]]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changed := RewriteWikiLinks([]byte(tt.content), rewrite)
+			if string(got) != tt.expected || changed != tt.changed {
+				t.Errorf("RewriteWikiLinks() = %q, %v; want %q, %v", got, changed, tt.expected, tt.changed)
+			}
+		})
+	}
+}
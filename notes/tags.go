@@ -0,0 +1,162 @@
+package notes
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NormalizeTag returns tag in the form tags are compared and stored in:
+// lower case, without a leading #
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
+
+// ValidateTag checks that a normalized tag can be stored and matched: it
+// must start with a letter and contain only letters, digits, -, _ and /
+func ValidateTag(tag string) error {
+	if tag == "" {
+		return fmt.Errorf("empty tag")
+	}
+	for i, r := range tag {
+		if i == 0 && !unicode.IsLetter(r) {
+			return fmt.Errorf("invalid tag %q: must start with a letter", tag)
+		}
+		if !isTagRune(r) {
+			return fmt.Errorf("invalid tag %q: only letters, digits, -, _ and / are allowed", tag)
+		}
+	}
+	return nil
+}
+
+// isTagRune reports whether r can appear in a tag after its first letter
+func isTagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '/'
+}
+
+// Hashtags returns the inline #tags in a markdown body, normalized, in order
+// of first appearance. A tag starts with # followed by a letter, after the
+// start of a line, whitespace or an opening bracket, so headings, issue
+// numbers like #12 and URL fragments are not tags. Code blocks and code
+// spans are skipped.
+func Hashtags(body []byte) []string {
+	var tags []string
+	seen := map[string]bool{}
+	fence := ""
+
+	for _, line := range strings.Split(string(body), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		}
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fence = trimmed[:3]
+			continue
+		}
+
+		for _, tag := range lineHashtags(line) {
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// lineHashtags returns the hashtags in a single line outside code spans
+func lineHashtags(line string) []string {
+	var tags []string
+	runes := []rune(line)
+	inCode := false
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if r == '`' {
+			inCode = !inCode
+			continue
+		}
+		if inCode || r != '#' {
+			continue
+		}
+		if i > 0 && !unicode.IsSpace(runes[i-1]) && !strings.ContainsRune("([{", runes[i-1]) {
+			continue
+		}
+		if i+1 >= len(runes) || !unicode.IsLetter(runes[i+1]) {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isTagRune(runes[end]) {
+			end++
+		}
+		tag := strings.TrimRight(string(runes[i+1:end]), "-_/")
+		tags = append(tags, NormalizeTag(tag))
+		i = end - 1
+	}
+	return tags
+}
+
+// FrontmatterTags returns the tags listed in the frontmatter, normalized, in
+// their order. A single string is split on commas and spaces.
+func (d *Document) FrontmatterTags() []string {
+	i := d.index("tags")
+	if i < 0 {
+		return nil
+	}
+
+	var values []string
+	node := d.meta.Content[i+1]
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			if item.Kind == yaml.ScalarNode {
+				values = append(values, item.Value)
+			}
+		}
+	case yaml.ScalarNode:
+		values = strings.FieldsFunc(node.Value, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+	}
+
+	var tags []string
+	seen := map[string]bool{}
+	for _, value := range values {
+		tag := NormalizeTag(value)
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// SetTags replaces the frontmatter tags, removing the key when tags is empty
+func (d *Document) SetTags(tags []string) error {
+	if len(tags) == 0 {
+		d.Delete("tags")
+		return nil
+	}
+	return d.Set("tags", tags)
+}
+
+// Tags returns the tags of the note, from its frontmatter and the hashtags
+// in its body, normalized and sorted
+func (d *Document) Tags() []string {
+	seen := map[string]bool{}
+	var tags []string
+	for _, tag := range append(d.FrontmatterTags(), Hashtags(d.Body)...) {
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
@@ -0,0 +1,139 @@
+package notes
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestHashtags(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{
+			name:     "tags in text",
+			body:     "Disk full on #Infra host, see #bug/disk.\n#todo first\n",
+			expected: []string{"infra", "bug/disk", "todo"},
+		},
+		{
+			name:     "repeated tags once",
+			body:     "#a #b #a\n",
+			expected: []string{"a", "b"},
+		},
+		{
+			name: "headings, numbers and fragments are not tags",
+			body: "# Heading\n## Sub\nfixes #12, see http://x.test/page#section and C#\n",
+		},
+		{
+			name:     "code is skipped",
+			body:     "run `echo #nope` then\n```\n#comment\n```\n(#yes)\n",
+			expected: []string{"yes"},
+		},
+		{
+			name:     "trailing punctuation",
+			body:     "tagged #wip- and #done_\n",
+			expected: []string{"wip", "done"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Hashtags([]byte(tt.body))
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Hashtags() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestDocumentTags(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		frontmatter []string
+		all         []string
+	}{
+		{
+			name:        "frontmatter list and hashtags",
+			content:     "---\ntags: [Infra, bug]\n---\nbody #done #infra\n",
+			frontmatter: []string{"infra", "bug"},
+			all:         []string{"bug", "done", "infra"},
+		},
+		{
+			name:        "frontmatter string",
+			content:     "---\ntags: infra, bug\n---\n",
+			frontmatter: []string{"infra", "bug"},
+			all:         []string{"bug", "infra"},
+		},
+		{
+			name:    "no frontmatter",
+			content: "#idea\n",
+			all:     []string{"idea"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(tt.content))
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			if got := doc.FrontmatterTags(); !reflect.DeepEqual(got, tt.frontmatter) {
+				t.Errorf("FrontmatterTags() = %v, want %v", got, tt.frontmatter)
+			}
+			if got := doc.Tags(); !reflect.DeepEqual(got, tt.all) {
+				t.Errorf("Tags() = %v, want %v", got, tt.all)
+			}
+		})
+	}
+}
+
+func TestSetTags(t *testing.T) {
+	doc, err := Parse([]byte("---\ntitle: x\ntags: [a]\n---\nbody\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	if err := doc.SetTags([]string{"a", "b"}); err != nil {
+		t.Fatalf("SetTags() unexpected error: %v", err)
+	}
+	content, _ := doc.Bytes()
+	if string(content) != "---\ntitle: x\ntags:\n  - a\n  - b\n---\nbody\n" {
+		t.Errorf("SetTags() content = %q", content)
+	}
+
+	if err := doc.SetTags(nil); err != nil {
+		t.Fatalf("SetTags(nil) unexpected error: %v", err)
+	}
+	content, _ = doc.Bytes()
+	if string(content) != "---\ntitle: x\n---\nbody\n" {
+		t.Errorf("SetTags(nil) content = %q", content)
+	}
+
+	// Removing the last tag of a note without other keys drops the block
+	doc, err = Parse([]byte("---\ntags: [a]\n---\nbody\n"))
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+	if err := doc.SetTags(nil); err != nil {
+		t.Fatalf("SetTags(nil) unexpected error: %v", err)
+	}
+	content, _ = doc.Bytes()
+	if string(content) != "body\n" || doc.HasFrontmatter() {
+		t.Errorf("SetTags(nil) content = %q, want the frontmatter removed", content)
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	for _, tag := range []string{"infra", "bug/disk", "v2-plan", "über"} {
+		if err := ValidateTag(tag); err != nil {
+			t.Errorf("ValidateTag(%q) unexpected error: %v", tag, err)
+		}
+	}
+	for _, tag := range []string{"", "2024", "two words", "a,b", "!done"} {
+		if err := ValidateTag(tag); err == nil {
+			t.Errorf("ValidateTag(%q) expected error", tag)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// OpenOptions holds the options of the open and last commands
+type OpenOptions struct {
+	// Ref is a path, list index or title fragment (open only)
+	Ref string
+	// Last selects the n-th newest note instead of Ref (last only)
+	Last int
+}
+
+// OpenNote resolves the note selected by opts in directory and opens it in
+// editor. It returns the path of the opened note.
+func OpenNote(directory string, opts OpenOptions, editor EditorLauncher) (string, error) {
+	var path string
+	var err error
+	if opts.Last > 0 {
+		path, err = LastNote(directory, opts.Last)
+	} else {
+		path, err = ResolveNote(directory, opts.Ref)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := editor.Launch(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// parseCount parses the optional positive count argument of commands like last
+func parseCount(args []string) (int, error) {
+	if len(args) > 1 {
+		return 0, fmt.Errorf("too many arguments")
+	}
+	if len(args) == 0 {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid count: %s", args[0])
+	}
+	return n, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestOpenNote(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-15_120000_retro.md",
+		"2025-08-16_143045_standup.md",
+	)
+
+	tests := []struct {
+		name     string
+		opts     OpenOptions
+		expected string
+	}{
+		{name: "by title", opts: OpenOptions{Ref: "retro"}, expected: "2025-08-15_120000_retro.md"},
+		{name: "by index", opts: OpenOptions{Ref: "1"}, expected: "2025-08-16_143045_standup.md"},
+		{name: "last", opts: OpenOptions{Last: 1}, expected: "2025-08-16_143045_standup.md"},
+		{name: "second last", opts: OpenOptions{Last: 2}, expected: "2025-08-15_120000_retro.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			editor := &MockEditor{}
+			path, err := OpenNote(dir, tt.opts, editor)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if filepath.Base(path) != tt.expected {
+				t.Errorf("OpenNote() = %s, want %s", filepath.Base(path), tt.expected)
+			}
+			if len(editor.CalledWith) != 1 || editor.CalledWith[0] != path {
+				t.Errorf("Editor called with %v, want [%s]", editor.CalledWith, path)
+			}
+		})
+	}
+}
+
+func TestOpenNoteErrors(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(), "2025-08-15_120000_retro.md")
+
+	editor := &MockEditor{}
+	if _, err := OpenNote(dir, OpenOptions{Ref: "standup"}, editor); err == nil {
+		t.Error("Expected error for unknown note")
+	}
+	if len(editor.CalledWith) != 0 {
+		t.Errorf("Editor should not be launched, got %v", editor.CalledWith)
+	}
+
+	if _, err := OpenNote(dir, OpenOptions{Ref: "retro"}, &MockEditor{ShouldFail: true}); err == nil {
+		t.Error("Expected editor error")
+	}
+}
+
+func TestParseArgsOpen(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    Command
+		expectError bool
+	}{
+		{name: "open title", args: []string{"scratch-note", "open", "meeting", "notes"}, expected: Command{Type: CommandTypeOpen, Open: OpenOptions{Ref: "meeting notes"}}},
+		{name: "open missing note", args: []string{"scratch-note", "open"}, expectError: true},
+		{name: "last default", args: []string{"scratch-note", "last"}, expected: Command{Type: CommandTypeLast, Open: OpenOptions{Last: 1}}},
+		{name: "last n", args: []string{"scratch-note", "last", "3"}, expected: Command{Type: CommandTypeLast, Open: OpenOptions{Last: 3}}},
+		{name: "last invalid", args: []string{"scratch-note", "last", "0"}, expectError: true},
+		{name: "last too many", args: []string{"scratch-note", "last", "1", "2"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseArgs(tt.args)
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %+v", cmd)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cmd, tt.expected) {
+				t.Errorf("ParseArgs() = %+v, want %+v", cmd, tt.expected)
+			}
+		})
+	}
+}
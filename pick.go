@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"scratch-note/picker"
+)
+
+// PickOptions holds the options of the pick command
+type PickOptions struct {
+	// Query pre-fills the picker's search field
+	Query string
+	// Print writes the selected path to stdout instead of opening it
+	Print bool
+}
+
+// PickNote lets the user choose a note in directory with the fuzzy picker
+// drawn on term, then opens it in editor or, with opts.Print, writes its
+// path to w. It returns picker.ErrCancelled when nothing was selected.
+func PickNote(directory string, opts PickOptions, term picker.Terminal, editor EditorLauncher, w io.Writer) (string, error) {
+	notes, err := collectNotes(directory)
+	if err != nil {
+		return "", err
+	}
+	if len(notes) == 0 {
+		return "", fmt.Errorf("no notes in %s", directory)
+	}
+	sortNotes(notes, SortCreated, false)
+
+	items := make([]picker.Item, len(notes))
+	for i, note := range notes {
+		content, err := os.ReadFile(note.Path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %v", note.Path, err)
+		}
+		items[i] = picker.Item{
+			Title: note.Created.Format("2006-01-02 15:04") + "  " + note.DisplayTitle(),
+			Body:  string(content),
+		}
+	}
+
+	selected, err := picker.Pick(term, items, opts.Query)
+	if err != nil {
+		return "", err
+	}
+	path := notes[selected].Path
+
+	if opts.Print {
+		fmt.Fprintln(w, path)
+		return path, nil
+	}
+	if err := editor.Launch(path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"scratch-note/picker"
+)
+
+// scriptedTerminal is a picker.Terminal feeding the given keystrokes
+type scriptedTerminal struct {
+	keys   []string
+	output bytes.Buffer
+}
+
+func (s *scriptedTerminal) Read(p []byte) (int, error) {
+	if len(s.keys) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, s.keys[0])
+	s.keys = s.keys[1:]
+	return n, nil
+}
+
+func (s *scriptedTerminal) Write(p []byte) (int, error) { return s.output.Write(p) }
+
+func (s *scriptedTerminal) Size() (int, int, error) { return 80, 24, nil }
+
+func (s *scriptedTerminal) Raw() (func() error, error) {
+	return func() error { return nil }, nil
+}
+
+func TestPickNote(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-08-15_120000_groceries.md", "milk\neggs\n")
+	writeTestNoteContent(t, dir, "2025-08-16_143045_retro.md", "deploy went fine\n")
+	writeTestNoteContent(t, dir, "2025-08-17_090000.md", "scratch\n")
+
+	t.Run("opens the selection", func(t *testing.T) {
+		editor := &MockEditor{}
+		var out bytes.Buffer
+		term := &scriptedTerminal{keys: []string{"deploy", "\r"}}
+		path, err := PickNote(dir, PickOptions{}, term, editor, &out)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !strings.Contains(term.output.String(), "(untitled)") {
+			t.Error("Untitled notes should be listed as (untitled)")
+		}
+		if filepath.Base(path) != "2025-08-16_143045_retro.md" {
+			t.Errorf("PickNote() = %s, want the note mentioning deploy", filepath.Base(path))
+		}
+		if len(editor.CalledWith) != 1 || editor.CalledWith[0] != path {
+			t.Errorf("Editor called with %v, want [%s]", editor.CalledWith, path)
+		}
+		if out.Len() != 0 {
+			t.Errorf("Nothing should be printed when opening, got %q", out.String())
+		}
+	})
+
+	t.Run("prints the selection", func(t *testing.T) {
+		editor := &MockEditor{}
+		var out bytes.Buffer
+		term := &scriptedTerminal{keys: []string{"\r"}}
+		path, err := PickNote(dir, PickOptions{Query: "groc", Print: true}, term, editor, &out)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if strings.TrimSpace(out.String()) != path || filepath.Base(path) != "2025-08-15_120000_groceries.md" {
+			t.Errorf("PickNote() printed %q, returned %s", out.String(), path)
+		}
+		if len(editor.CalledWith) != 0 {
+			t.Errorf("Editor should not be launched with --print, got %v", editor.CalledWith)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		editor := &MockEditor{}
+		_, err := PickNote(dir, PickOptions{}, &scriptedTerminal{keys: []string{"\x1b"}}, editor, io.Discard)
+		if !errors.Is(err, picker.ErrCancelled) {
+			t.Errorf("Expected picker.ErrCancelled, got %v", err)
+		}
+		if len(editor.CalledWith) != 0 {
+			t.Errorf("Editor should not be launched, got %v", editor.CalledWith)
+		}
+	})
+
+	t.Run("empty directory", func(t *testing.T) {
+		_, err := PickNote(t.TempDir(), PickOptions{}, &scriptedTerminal{}, &MockEditor{}, io.Discard)
+		if err == nil {
+			t.Error("Expected error for empty directory")
+		}
+	})
+}
+
+func TestParseArgsPick(t *testing.T) {
+	cmd, err := ParseArgs([]string{"scratch-note", "pick", "deploy", "retro", "--print"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if cmd.Type != CommandTypePick || cmd.Pick.Query != "deploy retro" || !cmd.Pick.Print {
+		t.Errorf("Unexpected command: %+v", cmd)
+	}
+}
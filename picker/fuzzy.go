@@ -0,0 +1,154 @@
+package picker
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scoring weights for subsequence matches
+const (
+	scoreMatch       = 16
+	bonusConsecutive = 8
+	bonusBoundary    = 8
+	penaltyGap       = 1
+	// Title matches are weighted so they rank above body-only matches
+	titleWeight = 2
+	titleBonus  = 64
+)
+
+// Item is a selectable entry. Both fields are matched against the query;
+// Title is shown in the list and Body in the preview.
+type Item struct {
+	Title string
+	Body  string
+}
+
+// Match is an item matching the query
+type Match struct {
+	// Index is the position of the item in the slice given to Rank
+	Index int
+	Score int
+	// Positions are the rune offsets in the title to highlight
+	Positions []int
+}
+
+// candidate holds the lower-cased runes of an item, computed once per pick
+type candidate struct {
+	title []rune
+	body  []rune
+}
+
+func newCandidates(items []Item) []candidate {
+	candidates := make([]candidate, len(items))
+	for i, item := range items {
+		candidates[i] = candidate{
+			title: []rune(strings.ToLower(item.Title)),
+			body:  []rune(strings.ToLower(item.Body)),
+		}
+	}
+	return candidates
+}
+
+// Rank returns the items matching query, best first. Every whitespace
+// separated word of the query must appear as a subsequence of the title or
+// the body, ignoring case. Ties keep the order of items.
+func Rank(items []Item, query string) []Match {
+	return rank(newCandidates(items), query)
+}
+
+func rank(candidates []candidate, query string) []Match {
+	var words [][]rune
+	for _, word := range strings.Fields(strings.ToLower(query)) {
+		words = append(words, []rune(word))
+	}
+
+	matches := make([]Match, 0, len(candidates))
+	for i, c := range candidates {
+		m := Match{Index: i}
+		matched := true
+		for _, word := range words {
+			if score, positions, ok := subsequence(word, c.title); ok {
+				m.Score += titleWeight*score + titleBonus
+				m.Positions = append(m.Positions, positions...)
+				continue
+			}
+			if score, _, ok := subsequence(word, c.body); ok {
+				m.Score += score
+				continue
+			}
+			matched = false
+			break
+		}
+		if matched {
+			matches = append(matches, m)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+	return matches
+}
+
+// subsequence finds pattern in text as a subsequence and scores the
+// shortest window containing it: matched runes score more when they are
+// consecutive or start a word, and every skipped rune costs a little.
+func subsequence(pattern, text []rune) (int, []int, bool) {
+	if len(pattern) == 0 {
+		return 0, nil, true
+	}
+
+	// Find the earliest end of a match...
+	end, pi := -1, 0
+	for i, r := range text {
+		if r == pattern[pi] {
+			pi++
+			if pi == len(pattern) {
+				end = i
+				break
+			}
+		}
+	}
+	if end < 0 {
+		return 0, nil, false
+	}
+
+	// ...then walk back to the latest start, giving the tightest window
+	start, pi := end, len(pattern)-1
+	for i := end; i >= 0; i-- {
+		if text[i] == pattern[pi] {
+			pi--
+			if pi < 0 {
+				start = i
+				break
+			}
+		}
+	}
+
+	positions := make([]int, 0, len(pattern))
+	score, prev := 0, -2
+	pi = 0
+	for i := start; i <= end && pi < len(pattern); i++ {
+		if text[i] != pattern[pi] {
+			continue
+		}
+		score += scoreMatch
+		if i == prev+1 {
+			score += bonusConsecutive
+		}
+		if i == 0 || !isWordRune(text[i-1]) {
+			score += bonusBoundary
+		}
+		positions = append(positions, i)
+		prev = i
+		pi++
+	}
+	score -= penaltyGap * (end - start + 1 - len(pattern))
+
+	return score, positions, true
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
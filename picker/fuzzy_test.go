@@ -0,0 +1,83 @@
+package picker
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubsequence(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		matched   bool
+		positions []int
+	}{
+		{pattern: "mtg", text: "meeting", matched: true, positions: []int{0, 3, 6}},
+		{pattern: "note", text: "meeting notes", matched: true, positions: []int{8, 9, 10, 11}},
+		{pattern: "gm", text: "meeting", matched: false},
+		{pattern: "", text: "anything", matched: true},
+		{pattern: "x", text: "", matched: false},
+	}
+
+	for _, tt := range tests {
+		_, positions, ok := subsequence([]rune(tt.pattern), []rune(tt.text))
+		if ok != tt.matched {
+			t.Errorf("subsequence(%q, %q) matched = %v, want %v", tt.pattern, tt.text, ok, tt.matched)
+			continue
+		}
+		if ok && !reflect.DeepEqual(positions, tt.positions) {
+			t.Errorf("subsequence(%q, %q) positions = %v, want %v", tt.pattern, tt.text, positions, tt.positions)
+		}
+	}
+}
+
+func TestSubsequenceScoring(t *testing.T) {
+	score := func(pattern, text string) int {
+		s, _, ok := subsequence([]rune(pattern), []rune(text))
+		if !ok {
+			t.Fatalf("subsequence(%q, %q) did not match", pattern, text)
+		}
+		return s
+	}
+
+	if score("deploy", "deploy") <= score("deploy", "d-e-p-l-o-y") {
+		t.Error("Consecutive matches should score above scattered ones")
+	}
+	if score("np", "new-plan") <= score("np", "snap") {
+		t.Error("Word-start matches should score above mid-word ones")
+	}
+}
+
+func TestRank(t *testing.T) {
+	items := []Item{
+		{Title: "2025-08-17 standup", Body: "talked about the deploy"},
+		{Title: "2025-08-16 deploy checklist", Body: "1. tag\n2. ship"},
+		{Title: "2025-08-15 groceries", Body: "milk, eggs"},
+		{Title: "2025-08-14 deployment retro", Body: "what went wrong"},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected []int
+	}{
+		{name: "empty query keeps order", query: "", expected: []int{0, 1, 2, 3}},
+		{name: "title matches before body matches", query: "deploy", expected: []int{1, 3, 0}},
+		{name: "fuzzy subsequence", query: "dpl chk", expected: []int{1}},
+		{name: "words may match title or body", query: "standup deploy", expected: []int{0}},
+		{name: "case insensitive", query: "MILK", expected: []int{2}},
+		{name: "no match", query: "zebra", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var indexes []int
+			for _, m := range Rank(items, tt.query) {
+				indexes = append(indexes, m.Index)
+			}
+			if !reflect.DeepEqual(indexes, tt.expected) {
+				t.Errorf("Rank(%q) = %v, want %v", tt.query, indexes, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,347 @@
+// Package picker implements an interactive fuzzy finder drawn directly on a
+// terminal in raw mode.
+package picker
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrCancelled is returned by Pick when the user leaves without selecting
+var ErrCancelled = errors.New("selection cancelled")
+
+// Terminal is the terminal the picker is drawn on
+type Terminal interface {
+	io.Reader
+	io.Writer
+	// Size returns the width and height of the terminal in cells
+	Size() (width, height int, err error)
+	// Raw switches the terminal to raw mode and returns a function restoring it
+	Raw() (restore func() error, err error)
+}
+
+// Fallback size when the terminal cannot report its own
+const (
+	defaultWidth  = 80
+	defaultHeight = 24
+)
+
+// Escape sequences used to draw the picker
+const (
+	escAltScreen   = "\x1b[?1049h"
+	escMainScreen  = "\x1b[?1049l"
+	escClear       = "\x1b[H\x1b[2J"
+	escReverse     = "\x1b[7m"
+	escBold        = "\x1b[1m"
+	escDim         = "\x1b[2m"
+	escReset       = "\x1b[0m"
+	escBoldOff     = "\x1b[22m"
+	escCursorToRow = "\x1b[%d;%dH"
+)
+
+const prompt = "> "
+
+// key is a decoded keypress
+type key int
+
+const (
+	keyRune key = iota
+	keyEnter
+	keyBackspace
+	keyDeleteWord
+	keyClear
+	keyUp
+	keyDown
+	keyCancel
+)
+
+type keyEvent struct {
+	key  key
+	rune rune
+}
+
+// Pick shows items on term, newest first as given, and lets the user narrow
+// them down by typing. It returns the index of the selected item, or
+// ErrCancelled when the user pressed Esc or Ctrl-C. query pre-fills the
+// search field.
+func Pick(term Terminal, items []Item, query string) (int, error) {
+	restore, err := term.Raw()
+	if err != nil {
+		return -1, err
+	}
+	defer restore()
+
+	fmt.Fprint(term, escAltScreen)
+	defer fmt.Fprint(term, escMainScreen)
+
+	p := &picker{items: items, candidates: newCandidates(items), query: []rune(query)}
+	p.update()
+
+	buf := make([]byte, 256)
+	for {
+		if err := p.draw(term); err != nil {
+			return -1, err
+		}
+
+		n, err := term.Read(buf)
+		if n == 0 && err != nil {
+			if err == io.EOF {
+				return -1, ErrCancelled
+			}
+			return -1, fmt.Errorf("failed to read from terminal: %v", err)
+		}
+
+		for _, ev := range decodeKeys(buf[:n]) {
+			switch ev.key {
+			case keyEnter:
+				if len(p.matches) == 0 {
+					continue
+				}
+				return p.matches[p.cursor].Index, nil
+			case keyCancel:
+				return -1, ErrCancelled
+			default:
+				p.handle(ev)
+			}
+		}
+	}
+}
+
+// picker holds the state of an interactive selection
+type picker struct {
+	items      []Item
+	candidates []candidate
+	query      []rune
+	matches    []Match
+	// cursor indexes matches; offset is the first match shown
+	cursor int
+	offset int
+}
+
+// update re-ranks the items for the current query
+func (p *picker) update() {
+	p.matches = rank(p.candidates, string(p.query))
+	p.cursor, p.offset = 0, 0
+}
+
+func (p *picker) handle(ev keyEvent) {
+	switch ev.key {
+	case keyRune:
+		p.query = append(p.query, ev.rune)
+		p.update()
+	case keyBackspace:
+		if len(p.query) > 0 {
+			p.query = p.query[:len(p.query)-1]
+			p.update()
+		}
+	case keyDeleteWord:
+		end := len(p.query)
+		for end > 0 && unicode.IsSpace(p.query[end-1]) {
+			end--
+		}
+		for end > 0 && !unicode.IsSpace(p.query[end-1]) {
+			end--
+		}
+		p.query = p.query[:end]
+		p.update()
+	case keyClear:
+		p.query = p.query[:0]
+		p.update()
+	case keyUp:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case keyDown:
+		if p.cursor < len(p.matches)-1 {
+			p.cursor++
+		}
+	}
+}
+
+// draw renders the whole screen: the query line, a status line, the
+// matching items and a preview of the selected one
+func (p *picker) draw(term Terminal) error {
+	width, height, err := term.Size()
+	if err != nil || width < 1 || height < 1 {
+		width, height = defaultWidth, defaultHeight
+	}
+
+	// Split the space below the two header lines between list and preview
+	listHeight := (height - 3) / 2
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	previewHeight := height - 3 - listHeight
+
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+listHeight {
+		p.offset = p.cursor - listHeight + 1
+	}
+
+	var lines []string
+	lines = append(lines, truncate(prompt+string(p.query), width))
+	lines = append(lines, escDim+truncate(fmt.Sprintf("  %d/%d", len(p.matches), len(p.items)), width)+escReset)
+
+	for row := 0; row < listHeight; row++ {
+		i := p.offset + row
+		if i >= len(p.matches) {
+			lines = append(lines, "")
+			continue
+		}
+		m := p.matches[i]
+		line := highlight(truncate(sanitize(p.items[m.Index].Title), width-2), m.Positions)
+		if i == p.cursor {
+			lines = append(lines, escReverse+"> "+line+escReset)
+		} else {
+			lines = append(lines, "  "+line)
+		}
+	}
+
+	if previewHeight > 0 {
+		lines = append(lines, escDim+strings.Repeat("─", width)+escReset)
+		if len(p.matches) > 0 {
+			body := p.items[p.matches[p.cursor].Index].Body
+			for i, line := range strings.SplitN(body, "\n", previewHeight+1) {
+				if i == previewHeight {
+					break
+				}
+				lines = append(lines, truncate(sanitize(line), width))
+			}
+		}
+	}
+
+	var frame strings.Builder
+	frame.WriteString(escClear)
+	frame.WriteString(strings.Join(lines, "\r\n"))
+	fmt.Fprintf(&frame, escCursorToRow, 1, utf8.RuneCountInString(prompt)+len(p.query)+1)
+
+	_, err = io.WriteString(term, frame.String())
+	return err
+}
+
+// decodeKeys splits the bytes of one terminal read into keypresses. An
+// escape sequence is only recognised when it arrives within the same read,
+// so a lone Esc cancels.
+func decodeKeys(buf []byte) []keyEvent {
+	var events []keyEvent
+	for i := 0; i < len(buf); {
+		b := buf[i]
+		switch {
+		case b == 0x1b:
+			if i+1 >= len(buf) {
+				events = append(events, keyEvent{key: keyCancel})
+				i++
+				continue
+			}
+			if buf[i+1] != '[' && buf[i+1] != 'O' {
+				// Alt+key: ignore the modifier
+				i++
+				continue
+			}
+			// CSI or SS3 sequence, terminated by a byte in 0x40-0x7e
+			end := i + 2
+			for end < len(buf) && (buf[end] < 0x40 || buf[end] > 0x7e) {
+				end++
+			}
+			if end < len(buf) {
+				switch buf[end] {
+				case 'A':
+					events = append(events, keyEvent{key: keyUp})
+				case 'B':
+					events = append(events, keyEvent{key: keyDown})
+				}
+			}
+			i = end + 1
+		case b == '\r' || b == '\n':
+			events = append(events, keyEvent{key: keyEnter})
+			i++
+		case b == 0x7f || b == 0x08:
+			events = append(events, keyEvent{key: keyBackspace})
+			i++
+		case b == 0x03 || b == 0x07 || b == 0x04:
+			// Ctrl-C, Ctrl-G, Ctrl-D
+			events = append(events, keyEvent{key: keyCancel})
+			i++
+		case b == 0x15:
+			// Ctrl-U
+			events = append(events, keyEvent{key: keyClear})
+			i++
+		case b == 0x17:
+			// Ctrl-W
+			events = append(events, keyEvent{key: keyDeleteWord})
+			i++
+		case b == 0x10 || b == 0x0b:
+			// Ctrl-P, Ctrl-K
+			events = append(events, keyEvent{key: keyUp})
+			i++
+		case b == 0x0e:
+			// Ctrl-N
+			events = append(events, keyEvent{key: keyDown})
+			i++
+		case b < 0x20:
+			// Other control characters
+			i++
+		default:
+			r, size := utf8.DecodeRune(buf[i:])
+			if r != utf8.RuneError {
+				events = append(events, keyEvent{key: keyRune, rune: r})
+			}
+			i += size
+		}
+	}
+	return events
+}
+
+// sanitize expands tabs and drops control characters that would corrupt the screen
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t':
+			return ' '
+		case unicode.IsControl(r):
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// truncate cuts s to at most width runes
+func truncate(s string, width int) string {
+	if width < 1 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width])
+}
+
+// highlight emboldens the runes of s at positions
+func highlight(s string, positions []int) string {
+	if len(positions) == 0 {
+		return s
+	}
+	marked := make(map[int]bool, len(positions))
+	for _, pos := range positions {
+		marked[pos] = true
+	}
+
+	var b strings.Builder
+	for i, r := range []rune(s) {
+		if marked[i] {
+			b.WriteString(escBold)
+			b.WriteRune(r)
+			b.WriteString(escBoldOff)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,153 @@
+package picker
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeTerminal replays keystrokes, one chunk per Read, and records the output
+type fakeTerminal struct {
+	input    [][]byte
+	output   bytes.Buffer
+	raw      bool
+	restored bool
+}
+
+func newFakeTerminal(keys ...string) *fakeTerminal {
+	term := &fakeTerminal{}
+	for _, k := range keys {
+		term.input = append(term.input, []byte(k))
+	}
+	return term
+}
+
+func (f *fakeTerminal) Read(p []byte) (int, error) {
+	if len(f.input) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, f.input[0])
+	f.input = f.input[1:]
+	return n, nil
+}
+
+func (f *fakeTerminal) Write(p []byte) (int, error) {
+	return f.output.Write(p)
+}
+
+func (f *fakeTerminal) Size() (int, int, error) {
+	return 60, 12, nil
+}
+
+func (f *fakeTerminal) Raw() (func() error, error) {
+	f.raw = true
+	return func() error {
+		f.restored = true
+		return nil
+	}, nil
+}
+
+var testItems = []Item{
+	{Title: "2025-08-17 standup", Body: "yesterday: deploy\ntoday: review"},
+	{Title: "2025-08-16 deploy checklist", Body: "1. tag\n2. ship"},
+	{Title: "2025-08-15 groceries", Body: "milk, eggs"},
+}
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		keys     []string
+		expected int
+	}{
+		{name: "enter selects the first item", keys: []string{"\r"}, expected: 0},
+		{name: "typing narrows the list", keys: []string{"g", "r", "o", "\r"}, expected: 2},
+		{name: "typed as one chunk", keys: []string{"milk\r"}, expected: 2},
+		{name: "arrow down moves the cursor", keys: []string{"\x1b[B", "\x1b[B", "\r"}, expected: 2},
+		{name: "arrow up stops at the top", keys: []string{"\x1b[B", "\x1b[A", "\x1b[A", "\r"}, expected: 0},
+		{name: "ctrl-n and ctrl-p", keys: []string{"\x0e\x0e\x10", "\r"}, expected: 1},
+		{name: "backspace widens the list", keys: []string{"milkx", "\x7f", "\r"}, expected: 2},
+		{name: "ctrl-u clears the query", keys: []string{"groceries", "\x15", "\r"}, expected: 0},
+		{name: "pre-filled query", query: "checklist", keys: []string{"\r"}, expected: 1},
+		{name: "enter without matches is ignored", keys: []string{"zzz", "\r", "\x15", "\r"}, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			term := newFakeTerminal(tt.keys...)
+			selected, err := Pick(term, testItems, tt.query)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if selected != tt.expected {
+				t.Errorf("Pick() = %d, want %d", selected, tt.expected)
+			}
+			if !term.raw || !term.restored {
+				t.Errorf("Terminal should be put in raw mode and restored (raw=%v, restored=%v)", term.raw, term.restored)
+			}
+		})
+	}
+}
+
+func TestPickCancel(t *testing.T) {
+	for name, keys := range map[string][]string{
+		"escape": {"dep", "\x1b"},
+		"ctrl-c": {"\x03"},
+		"eof":    {"dep"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			term := newFakeTerminal(keys...)
+			_, err := Pick(term, testItems, "")
+			if !errors.Is(err, ErrCancelled) {
+				t.Errorf("Expected ErrCancelled, got %v", err)
+			}
+			if !term.restored {
+				t.Error("Terminal should be restored after cancelling")
+			}
+		})
+	}
+}
+
+func TestPickDrawsListAndPreview(t *testing.T) {
+	term := newFakeTerminal("check", "\r")
+	if _, err := Pick(term, testItems, ""); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out := term.output.String()
+	if !strings.HasPrefix(out, escAltScreen) || !strings.HasSuffix(out, escMainScreen) {
+		t.Error("Picker should draw on the alternate screen and leave it")
+	}
+
+	// The last frame is drawn after typing the query
+	frame := out[strings.LastIndex(out, escClear):]
+	for _, expected := range []string{"> check", "1/3", "2025-08-16 deploy", "1. tag", "2. ship"} {
+		if !strings.Contains(frame, expected) {
+			t.Errorf("Final frame should contain %q, got %q", expected, frame)
+		}
+	}
+	if strings.Contains(frame, "groceries") {
+		t.Errorf("Final frame should not list filtered items, got %q", frame)
+	}
+}
+
+func TestDecodeKeys(t *testing.T) {
+	events := decodeKeys([]byte("aé\x1b[A\x1b[1;5C\x1bx\r"))
+	expected := []keyEvent{
+		{key: keyRune, rune: 'a'},
+		{key: keyRune, rune: 'é'},
+		{key: keyUp},
+		{key: keyRune, rune: 'x'},
+		{key: keyEnter},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("decodeKeys() = %v, want %v", events, expected)
+	}
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("event %d = %v, want %v", i, events[i], expected[i])
+		}
+	}
+}
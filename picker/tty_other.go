@@ -0,0 +1,26 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package picker
+
+import (
+	"errors"
+	"os"
+)
+
+// TTY is the controlling terminal of the process
+type TTY struct {
+	*os.File
+}
+
+// OpenTTY reports that the picker is not supported on this platform
+func OpenTTY() (*TTY, error) {
+	return nil, errors.New("the interactive picker is not supported on this platform")
+}
+
+func (t *TTY) Size() (int, int, error) {
+	return 0, 0, errors.New("terminal size not supported on this platform")
+}
+
+func (t *TTY) Raw() (func() error, error) {
+	return nil, errors.New("raw mode not supported on this platform")
+}
@@ -0,0 +1,69 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package picker
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// TTY is the controlling terminal of the process
+type TTY struct {
+	*os.File
+}
+
+// OpenTTY opens the controlling terminal, so the picker can be drawn even
+// when standard input or output is redirected
+func OpenTTY() (*TTY, error) {
+	file, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open terminal: %v", err)
+	}
+	return &TTY{File: file}, nil
+}
+
+type winsize struct {
+	Row, Col, Xpixel, Ypixel uint16
+}
+
+func (t *TTY) Size() (int, int, error) {
+	var ws winsize
+	if err := ioctl(t.Fd(), syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, fmt.Errorf("failed to get terminal size: %v", err)
+	}
+	return int(ws.Col), int(ws.Row), nil
+}
+
+func (t *TTY) Raw() (func() error, error) {
+	var saved syscall.Termios
+	if err := ioctl(t.Fd(), ioctlGetTermios, unsafe.Pointer(&saved)); err != nil {
+		return nil, fmt.Errorf("failed to read terminal mode: %v", err)
+	}
+
+	raw := saved
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP |
+		syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+
+	if err := ioctl(t.Fd(), ioctlSetTermios, unsafe.Pointer(&raw)); err != nil {
+		return nil, fmt.Errorf("failed to enter raw mode: %v", err)
+	}
+
+	return func() error {
+		return ioctl(t.Fd(), ioctlSetTermios, unsafe.Pointer(&saved))
+	}, nil
+}
+
+func ioctl(fd uintptr, request uintptr, arg unsafe.Pointer) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, request, uintptr(arg))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"scratch-note/notes"
+	"scratch-note/utils"
+)
+
+// RenameOptions holds the options of the rename command
+type RenameOptions struct {
+	// Ref is a path, list index or title fragment of the note
+	Ref string
+	// Title is the new title
+	Title string
+}
+
+// RenameNote gives the note opts.Ref refers to the title opts.Title. The
+// note keeps its timestamp and directory and gets the slug of the new title;
+// a frontmatter title is updated too. [[wiki-links]] in any note that
+// resolved to the note alone are rewritten in the form they were written in.
+// Each change is reported to w. It returns the new path of the note.
+func RenameNote(directory string, opts RenameOptions, w io.Writer) (string, error) {
+	slug := noteNames.Slug(opts.Title)
+	if slug == "" {
+		return "", fmt.Errorf("new title is empty")
+	}
+
+	path, err := ResolveUniqueNote(directory, opts.Ref)
+	if err != nil {
+		return "", err
+	}
+	root, err := filepath.Abs(directory)
+	if err != nil {
+		return "", err
+	}
+	if path, err = filepath.Abs(path); err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not in the scratch-note directory", path)
+	}
+	rel = filepath.ToSlash(rel)
+	old, format, err := parseNoteName(rel)
+	if err != nil {
+		return "", fmt.Errorf("cannot rename %s: only timestamped notes have a title", rel)
+	}
+
+	renamed := old
+	renamed.Slug = slug
+	target, err := freeRenameTarget(directory, format, rel, renamed)
+	if err != nil {
+		return "", err
+	}
+
+	// Links are resolved before the rename, against the notes they were
+	// written for
+	_, links, err := collectLinks(directory)
+	if err != nil {
+		return "", err
+	}
+
+	// The file is renamed before its title is updated, and renamed back if
+	// that fails, so a failure never leaves the note half renamed
+	newPath := filepath.Join(directory, filepath.FromSlash(target))
+	if target != rel {
+		if err := os.Rename(path, newPath); err != nil {
+			return "", fmt.Errorf("failed to rename note: %v", err)
+		}
+	}
+	if err := updateFrontmatterTitle(newPath, opts.Title); err != nil {
+		if target != rel {
+			os.Rename(newPath, path)
+		}
+		return "", err
+	}
+	if target != rel {
+		fmt.Fprintf(w, "Renamed %s -> %s\n", rel, target)
+	}
+
+	if err := rewriteRenamedLinks(directory, links, rel, target, opts.Title, w); err != nil {
+		return newPath, err
+	}
+	return newPath, nil
+}
+
+// freeRenameTarget returns the name rel, in the given format, gets when
+// renamed to n, raising the sequence number of n when the name is taken by
+// another note
+func freeRenameTarget(directory string, format *utils.FileNameFormat, rel string, n utils.NoteName) (string, error) {
+	for seq := max(n.Seq, 1); seq <= maxNoteSeq; seq++ {
+		if seq > 1 {
+			n.Seq = seq
+		}
+		target, err := format.Rename(rel, n)
+		if err != nil {
+			return "", err
+		}
+		if target == rel {
+			return target, nil
+		}
+		if _, err := os.Lstat(filepath.Join(directory, filepath.FromSlash(target))); os.IsNotExist(err) {
+			return target, nil
+		}
+	}
+	return "", fmt.Errorf("failed to rename note: too many notes named %s", n.Slug)
+}
+
+// updateFrontmatterTitle sets the frontmatter title of the note at path to
+// title, if the note has one
+func updateFrontmatterTitle(path, title string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %v", err)
+	}
+	doc, err := notes.Parse(content)
+	if err != nil || !doc.Has("title") {
+		// Notes without valid frontmatter only get a new filename
+		return nil
+	}
+
+	if err := doc.Set("title", title); err != nil {
+		return err
+	}
+	updated, err := doc.Bytes()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, updated, 0644); err != nil {
+		return fmt.Errorf("failed to write note: %v", err)
+	}
+	return nil
+}
+
+// rewriteRenamedLinks rewrites links, resolved before the note at oldRel
+// was renamed to newRel, that named that note and no other. Each keeps its
+// form: a path, filename or timestamp link gets the new one, and a title
+// link gets the new title.
+func rewriteRenamedLinks(directory string, links []NoteLink, oldRel, newRel, title string, w io.Writer) error {
+	oldNames, newNames := linkNames(oldRel), linkNames(newRel)
+	rewrites := map[string]string{}
+	var sources []string
+	for _, link := range links {
+		if len(link.Matches) != 1 || link.Matches[0] != oldRel {
+			continue
+		}
+		rewrites[link.Target] = title
+		for i, name := range oldNames {
+			if strings.EqualFold(link.Target, name) {
+				rewrites[link.Target] = newNames[i]
+				break
+			}
+		}
+		source := link.Source
+		if source == oldRel {
+			source = newRel
+		}
+		if !slices.Contains(sources, source) {
+			sources = append(sources, source)
+		}
+	}
+
+	rewrite := func(target string) (string, bool) {
+		renamed, ok := rewrites[target]
+		return renamed, ok
+	}
+	for _, source := range sources {
+		path := filepath.Join(directory, filepath.FromSlash(source))
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read note: %v", err)
+		}
+		updated, changed := notes.RewriteWikiLinks(content, rewrite)
+		if !changed {
+			continue
+		}
+		if err := os.WriteFile(path, updated, 0644); err != nil {
+			return fmt.Errorf("failed to write note: %v", err)
+		}
+		fmt.Fprintf(w, "Updated links in %s\n", source)
+	}
+	return nil
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRenameNote(t *testing.T) {
+	useLayout(t, "monthly")
+	dir := t.TempDir()
+	path := writeTestNoteContent(t, dir, "2025/09/2025-09-01_100000_disk-full.md",
+		"---\ntitle: Disk full\ntags: [infra]\n---\nbody [[disk-full]]\n")
+	other := writeTestNoteContent(t, dir, "2025/09/2025-09-02_100000_standup.md",
+		"See [[2025-09-01_100000_disk-full]], [[Disk Full#Fix|the fix]] and `[[disk-full]]`.\n")
+	untouched := writeTestNoteContent(t, dir, "2025/09/2025-09-03_100000_retro.md", "[[standup]]\n")
+
+	var out bytes.Buffer
+	newPath, err := RenameNote(dir, RenameOptions{Ref: "disk", Title: "Out of disk space"}, &out)
+	if err != nil {
+		t.Fatalf("RenameNote() unexpected error: %v", err)
+	}
+
+	// The timestamp and the monthly directory are kept
+	expectedPath := filepath.Join(dir, "2025", "09", "2025-09-01_100000_Out-of-disk-space.md")
+	if newPath != expectedPath {
+		t.Errorf("RenameNote() = %s, want %s", newPath, expectedPath)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Old file should be gone")
+	}
+
+	content, err := os.ReadFile(newPath)
+	if err != nil {
+		t.Fatalf("Failed to read renamed note: %v", err)
+	}
+	expected := "---\ntitle: Out of disk space\ntags: [infra]\n---\nbody [[Out of disk space]]\n"
+	if string(content) != expected {
+		t.Errorf("Renamed note = %q, want %q", content, expected)
+	}
+
+	content, _ = os.ReadFile(other)
+	expected = "See [[2025-09-01_100000_Out-of-disk-space]], [[Out of disk space#Fix|the fix]] and `[[disk-full]]`.\n"
+	if string(content) != expected {
+		t.Errorf("Linking note = %q, want %q", content, expected)
+	}
+	if content, _ := os.ReadFile(untouched); string(content) != "[[standup]]\n" {
+		t.Errorf("Unrelated note changed: %q", content)
+	}
+
+	for _, want := range []string{"Renamed 2025/09/2025-09-01_100000_disk-full.md -> 2025/09/2025-09-01_100000_Out-of-disk-space.md", "Updated links in 2025/09/2025-09-02_100000_standup.md"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("RenameNote() output missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRenameNoteLinkForms(t *testing.T) {
+	useLayout(t, "monthly")
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025/09/2025-09-01_100000_disk-full.md", "first\n")
+	linking := writeTestNoteContent(t, dir, "2025/09/2025-09-02_100000_standup.md", "")
+
+	tests := []struct {
+		name     string
+		link     string
+		expected string
+	}{
+		{"path", "[[2025/09/2025-09-01_100000_disk-full]]", "[[2025/09/2025-09-01_100000_outage]]"},
+		{"path with extension", "[[2025/09/2025-09-01_100000_disk-full.md]]", "[[2025/09/2025-09-01_100000_outage.md]]"},
+		{"filename", "[[2025-09-01_100000_disk-full]]", "[[2025-09-01_100000_outage]]"},
+		{"filename with extension", "[[2025-09-01_100000_Disk-Full.MD|disk]]", "[[2025-09-01_100000_outage.md|disk]]"},
+		{"timestamp, kept by the rename", "[[2025-09-01_100000#Fix]]", "[[2025-09-01_100000#Fix]]"},
+		{"title", "[[Disk full]]", "[[outage]]"},
+	}
+
+	var content, expected strings.Builder
+	for _, tt := range tests {
+		content.WriteString(tt.link + "\n")
+		expected.WriteString(tt.expected + "\n")
+	}
+	if err := os.WriteFile(linking, []byte(content.String()), 0644); err != nil {
+		t.Fatalf("Failed to write note: %v", err)
+	}
+
+	newPath, err := RenameNote(dir, RenameOptions{Ref: "disk", Title: "outage"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("RenameNote() unexpected error: %v", err)
+	}
+	if filepath.Base(newPath) != "2025-09-01_100000_outage.md" {
+		t.Fatalf("RenameNote() = %s", newPath)
+	}
+
+	got, _ := os.ReadFile(linking)
+	gotLines := strings.Split(string(got), "\n")
+	for i, tt := range tests {
+		if gotLines[i] != tt.expected {
+			t.Errorf("%s link = %s, want %s", tt.name, gotLines[i], tt.expected)
+		}
+	}
+
+	report, err := CheckLinks(dir)
+	if err != nil || len(report.Broken) != 0 || len(report.Ambiguous) != 0 {
+		t.Errorf("CheckLinks() after rename = %+v, %v; want every link to resolve", report, err)
+	}
+}
+
+func TestRenameNoteSharedTitle(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-09-01_100000_standup.md", "first\n")
+	writeTestNoteContent(t, dir, "2025-09-02_100000_standup.md", "second\n")
+	linking := writeTestNoteContent(t, dir, "2025-09-03_100000_retro.md", "[[standup]] [[2025-09-01_100000_standup.md]]\n")
+
+	newPath, err := RenameNote(dir, RenameOptions{Ref: "2025-09-01", Title: "Kickoff"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("RenameNote() unexpected error: %v", err)
+	}
+	if filepath.Base(newPath) != "2025-09-01_100000_Kickoff.md" {
+		t.Errorf("RenameNote() = %s", newPath)
+	}
+
+	// The title link may mean the other standup, so only the filename link changes
+	content, _ := os.ReadFile(linking)
+	expected := "[[standup]] [[2025-09-01_100000_Kickoff.md]]\n"
+	if string(content) != expected {
+		t.Errorf("Linking note = %q, want %q", content, expected)
+	}
+}
+
+func TestRenameNoteTakenName(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-09-01_100000_standup.md", "first\n")
+	writeTestNoteContent(t, dir, "2025-09-01_100000_kickoff.md", "second\n")
+
+	newPath, err := RenameNote(dir, RenameOptions{Ref: "standup", Title: "kickoff"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("RenameNote() unexpected error: %v", err)
+	}
+	if filepath.Base(newPath) != "2025-09-01_100000-2_kickoff.md" {
+		t.Errorf("RenameNote() = %s, want a sequence suffix", newPath)
+	}
+}
+
+func TestRenameNoteKeepsSeq(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-09-01_100000_b.md", "first\n")
+	writeTestNoteContent(t, dir, "2025-09-01_100000-2_b.md", "second\n")
+	writeTestNoteContent(t, dir, "2025-09-01_100000-2_d.md", "third\n")
+
+	newPath, err := RenameNote(dir, RenameOptions{Ref: "2025-09-01_100000-2_b", Title: "c"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("RenameNote() unexpected error: %v", err)
+	}
+	if filepath.Base(newPath) != "2025-09-01_100000-2_c.md" {
+		t.Errorf("RenameNote() = %s, want the sequence number kept", newPath)
+	}
+
+	// A taken name raises the sequence number from there
+	newPath, err = RenameNote(dir, RenameOptions{Ref: "2025-09-01_100000-2_c", Title: "d"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("RenameNote() unexpected error: %v", err)
+	}
+	if filepath.Base(newPath) != "2025-09-01_100000-3_d.md" {
+		t.Errorf("RenameNote() = %s, want the next free sequence number", newPath)
+	}
+}
+
+func TestRenameNoteRelativeDirectory(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTestNoteContent(t, "notes", "2025-09-01_100000_standup.md", "note\n")
+	linking := writeTestNoteContent(t, "notes", "2025-09-02_100000_retro.md", "[[standup]]\n")
+
+	// A path resolves to an absolute path, while the directory stays relative
+	newPath, err := RenameNote("notes", RenameOptions{Ref: filepath.Join("notes", "2025-09-01_100000_standup.md"), Title: "kickoff"}, &bytes.Buffer{})
+	if err != nil {
+		t.Fatalf("RenameNote() unexpected error: %v", err)
+	}
+	if expected := filepath.Join("notes", "2025-09-01_100000_kickoff.md"); newPath != expected {
+		t.Errorf("RenameNote() = %s, want %s", newPath, expected)
+	}
+	if content, _ := os.ReadFile(linking); string(content) != "[[kickoff]]\n" {
+		t.Errorf("Linking note = %q, want %q", content, "[[kickoff]]\n")
+	}
+}
+
+func TestRenameNoteErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-09-01_100000_standup.md", "note\n")
+	writeTestNoteContent(t, dir, "2025-09-02.md", "daily\n")
+
+	tests := []struct {
+		name string
+		opts RenameOptions
+	}{
+		{name: "empty title", opts: RenameOptions{Ref: "standup", Title: " / ? "}},
+		{name: "daily note", opts: RenameOptions{Ref: "2025-09-02", Title: "Monday"}},
+		{name: "no match", opts: RenameOptions{Ref: "retro", Title: "Review"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := RenameNote(dir, tt.opts, &bytes.Buffer{}); err == nil {
+				t.Error("RenameNote() expected error")
+			}
+		})
+	}
+}
+
+func TestParseArgsRename(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    Command
+		expectError bool
+	}{
+		{
+			name:     "quoted title",
+			args:     []string{"scratch-note", "rename", "2", "Out of disk space"},
+			expected: Command{Type: CommandTypeRename, Rename: RenameOptions{Ref: "2", Title: "Out of disk space"}},
+		},
+		{
+			name:     "unquoted title",
+			args:     []string{"scratch-note", "mv", "standup", "weekly", "sync"},
+			expected: Command{Type: CommandTypeRename, Rename: RenameOptions{Ref: "standup", Title: "weekly sync"}},
+		},
+		{name: "missing title", args: []string{"scratch-note", "rename", "standup"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseArgs(tt.args)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cmd, tt.expected) {
+				t.Errorf("ParseArgs() = %+v, want %+v", cmd, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FindNotes returns the notes in directory that ref refers to, newest first.
+// ref is tried, in order, as:
+//   - the path of an existing file, inside or outside directory
+//   - a 1-based index into the notes listed newest first, as shown by list
+//   - a case-insensitive fragment of the filename, e.g. part of the title
+//     or timestamp; notes whose title equals the fragment come first
+//
+// A number past the last note, such as a year or time, is a fragment.
+func FindNotes(directory, ref string) ([]NoteEntry, error) {
+	if strings.TrimSpace(ref) == "" {
+		return nil, fmt.Errorf("no note given")
+	}
+
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		path, err := filepath.Abs(ref)
+		if err != nil {
+			return nil, err
+		}
+		return []NoteEntry{{Path: path, Modified: info.ModTime()}}, nil
+	}
+
+	notes, err := collectNotes(directory)
+	if err != nil {
+		return nil, err
+	}
+	sortNotes(notes, SortCreated, false)
+
+	index, indexErr := strconv.Atoi(ref)
+	if indexErr == nil && index >= 1 && index <= len(notes) {
+		return []NoteEntry{notes[index-1]}, nil
+	}
+
+	fragment := strings.ToLower(noteNames.Slug(ref))
+	var exact, partial []NoteEntry
+	for _, note := range notes {
+		name := strings.ToLower(strings.TrimSuffix(filepath.Base(note.Path), filepath.Ext(note.Path)))
+		switch {
+		case strings.ToLower(note.Title) == fragment:
+			exact = append(exact, note)
+		case strings.Contains(name, fragment):
+			partial = append(partial, note)
+		}
+	}
+
+	matches := append(exact, partial...)
+	if len(matches) == 0 && indexErr == nil {
+		return nil, fmt.Errorf("no note at index %d (%d notes) and no note matches %q", index, len(notes), ref)
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no note matches %q", ref)
+	}
+	return matches, nil
+}
+
+// ResolveNote returns the path of the note ref refers to, preferring the
+// newest note when several match
+func ResolveNote(directory, ref string) (string, error) {
+	matches, err := FindNotes(directory, ref)
+	if err != nil {
+		return "", err
+	}
+	return matches[0].Path, nil
+}
+
+// ResolveUniqueNote is like ResolveNote but fails when ref matches more than
+// one note, for commands that modify the note
+func ResolveUniqueNote(directory, ref string) (string, error) {
+	matches, err := FindNotes(directory, ref)
+	if err != nil {
+		return "", err
+	}
+	if len(matches) > 1 {
+		var names []string
+		for _, note := range matches {
+			names = append(names, "  "+filepath.Base(note.Path))
+		}
+		return "", fmt.Errorf("%q matches %d notes:\n%s", ref, len(matches), strings.Join(names, "\n"))
+	}
+	return matches[0].Path, nil
+}
+
+// LastNote returns the path of the n-th newest note in directory (1 for the newest)
+func LastNote(directory string, n int) (string, error) {
+	if n < 1 {
+		return "", fmt.Errorf("invalid note count: %d", n)
+	}
+
+	notes, err := collectNotes(directory)
+	if err != nil {
+		return "", err
+	}
+	if len(notes) == 0 {
+		return "", fmt.Errorf("no notes in %s", directory)
+	}
+	if n > len(notes) {
+		return "", fmt.Errorf("only %d notes in %s", len(notes), directory)
+	}
+
+	sortNotes(notes, SortCreated, false)
+	return notes[n-1].Path, nil
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindNotes(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-15_120000_meeting.md",
+		"2025-08-16_143045_meeting-notes.md",
+		"2025-08-17_090000_standup.md",
+		"2025-08-17_100000.md",
+		"README.md",
+	)
+	outside := writeTestNotes(t, t.TempDir(), "elsewhere.md")
+
+	tests := []struct {
+		name        string
+		ref         string
+		expected    []string
+		expectError bool
+	}{
+		{
+			name:     "index 1 is the newest note",
+			ref:      "1",
+			expected: []string{"2025-08-17_100000.md"},
+		},
+		{
+			name:     "index follows list order",
+			ref:      "3",
+			expected: []string{"2025-08-16_143045_meeting-notes.md"},
+		},
+		{
+			name:     "exact title comes before partial matches",
+			ref:      "Meeting",
+			expected: []string{"2025-08-15_120000_meeting.md", "2025-08-16_143045_meeting-notes.md"},
+		},
+		{
+			name:     "fragment is cleaned like a title",
+			ref:      "meeting notes",
+			expected: []string{"2025-08-16_143045_meeting-notes.md"},
+		},
+		{
+			name:     "timestamp fragment",
+			ref:      "2025-08-17",
+			expected: []string{"2025-08-17_100000.md", "2025-08-17_090000_standup.md"},
+		},
+		{
+			name:     "existing path",
+			ref:      filepath.Join(outside, "elsewhere.md"),
+			expected: []string{"elsewhere.md"},
+		},
+		{
+			name:     "number past the last note is a time fragment",
+			ref:      "143045",
+			expected: []string{"2025-08-16_143045_meeting-notes.md"},
+		},
+		{
+			name:     "number past the last note is a year fragment",
+			ref:      "2025",
+			expected: []string{"2025-08-17_100000.md", "2025-08-17_090000_standup.md", "2025-08-16_143045_meeting-notes.md", "2025-08-15_120000_meeting.md"},
+		},
+		{
+			name:        "index out of range matching no note",
+			ref:         "42",
+			expectError: true,
+		},
+		{
+			name:        "no match",
+			ref:         "retro",
+			expectError: true,
+		},
+		{
+			name:        "empty reference",
+			ref:         " ",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches, err := FindNotes(dir, tt.ref)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %v", matches)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			var names []string
+			for _, note := range matches {
+				names = append(names, filepath.Base(note.Path))
+			}
+			if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("FindNotes(%q) = %v, want %v", tt.ref, names, tt.expected)
+			}
+		})
+	}
+}
+
+func TestResolveUniqueNote(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-15_120000_meeting.md",
+		"2025-08-16_143045_meeting-notes.md",
+	)
+
+	path, err := ResolveNote(dir, "meeting")
+	if err != nil || filepath.Base(path) != "2025-08-15_120000_meeting.md" {
+		t.Errorf("ResolveNote() = %q, %v; want the exact title match", path, err)
+	}
+
+	if _, err := ResolveUniqueNote(dir, "2025-08"); err == nil {
+		t.Error("Expected error for ambiguous reference")
+	}
+
+	path, err = ResolveUniqueNote(dir, "notes")
+	if err != nil || filepath.Base(path) != "2025-08-16_143045_meeting-notes.md" {
+		t.Errorf("ResolveUniqueNote() = %q, %v", path, err)
+	}
+}
+
+func TestLastNote(t *testing.T) {
+	dir := writeTestNotes(t, t.TempDir(),
+		"2025-08-15_120000.md",
+		"2025-08-16_143045.md",
+		"2025-08-16_143045-2.md",
+	)
+
+	for n, expected := range map[int]string{1: "2025-08-16_143045-2.md", 2: "2025-08-16_143045.md", 3: "2025-08-15_120000.md"} {
+		path, err := LastNote(dir, n)
+		if err != nil {
+			t.Fatalf("LastNote(%d) unexpected error: %v", n, err)
+		}
+		if filepath.Base(path) != expected {
+			t.Errorf("LastNote(%d) = %s, want %s", n, filepath.Base(path), expected)
+		}
+	}
+
+	for _, n := range []int{0, 4} {
+		if _, err := LastNote(dir, n); err == nil {
+			t.Errorf("LastNote(%d) expected error", n)
+		}
+	}
+
+	if _, err := LastNote(t.TempDir(), 1); err == nil {
+		t.Error("Expected error for empty directory")
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"scratch-note/search"
+)
+
+// snippetWidth is the maximum width of the line excerpt shown for a hit
+const snippetWidth = 100
+
+// ANSI escape sequences used to highlight matches on a terminal
+const (
+	ansiHighlight = "\x1b[1;31m"
+	ansiReset     = "\x1b[0m"
+)
+
+// SearchOptions holds the options of the search command
+type SearchOptions struct {
+	Query string
+	// Regex treats Query as a regular expression
+	Regex bool
+	JSON  bool
+	// Color highlights matches with ANSI escapes
+	Color bool
+	// NoColor disables highlighting even on a terminal
+	NoColor bool
+	// Workers bounds the number of notes read concurrently; 0 means one per CPU
+	Workers int
+	// NoIndex scans every note even when a search index exists
+	NoIndex bool
+}
+
+// IndexOptions holds the options of the index command
+type IndexOptions struct {
+	// Rebuild discards the existing index and indexes every note again
+	Rebuild bool
+}
+
+// searchHitJSON is the JSON representation of a search hit
+type searchHitJSON struct {
+	Path string `json:"path"`
+	File string `json:"file"`
+	// Line is 0 when the note matched as a whole but no single line did
+	Line   int     `json:"line"`
+	Text   string  `json:"text"`
+	Ranges [][]int `json:"ranges"`
+}
+
+// newMatcher builds the matcher for the query in opts
+func newMatcher(opts SearchOptions) (search.Matcher, error) {
+	if opts.Regex {
+		return search.NewRegexp(opts.Query)
+	}
+	return search.ParseQuery(opts.Query)
+}
+
+// notePaths returns the paths of every note in directory, newest first
+func notePaths(directory string) ([]string, error) {
+	notes, err := collectNotes(directory)
+	if err != nil {
+		return nil, err
+	}
+	sortNotes(notes, SortCreated, false)
+
+	paths := make([]string, len(notes))
+	for i, note := range notes {
+		paths[i] = note.Path
+	}
+	return paths, nil
+}
+
+// SearchNotes searches the notes in directory for the query and writes the
+// matching lines to w. When the search index at indexPath exists, it is
+// brought up to date and hits are ranked by relevance; otherwise, or in
+// regex mode, every note is scanned and hits are listed newest note first.
+func SearchNotes(directory, indexPath string, opts SearchOptions, w io.Writer) error {
+	matcher, err := newMatcher(opts)
+	if err != nil {
+		return err
+	}
+
+	paths, err := notePaths(directory)
+	if err != nil {
+		return err
+	}
+
+	if query, ok := matcher.(*search.Query); ok && indexPath != "" && !opts.NoIndex {
+		ix, err := openIndex(indexPath, directory, paths, false)
+		if err != nil {
+			return err
+		}
+		if ix != nil {
+			paths = paths[:0]
+			for _, ranked := range ix.Search(query) {
+				paths = append(paths, ranked.Path)
+			}
+		}
+	}
+
+	results, err := search.Scan(paths, matcher, opts.Workers)
+	if err != nil {
+		return err
+	}
+
+	if opts.JSON {
+		return writeSearchJSON(w, results)
+	}
+	writeSearchResults(w, results, opts.Color)
+	return nil
+}
+
+// openIndex loads the search index at indexPath and updates it for paths,
+// saving it when anything changed. A corrupt or outdated index is rebuilt.
+// It returns nil without error when no index exists, unless create is set.
+func openIndex(indexPath, directory string, paths []string, create bool) (*search.Index, error) {
+	fresh := false
+	ix, err := search.LoadIndex(indexPath, directory)
+	switch {
+	case errors.Is(err, search.ErrIndexNotFound):
+		if !create {
+			return nil, nil
+		}
+		ix, fresh = search.NewIndex(directory), true
+	case errors.Is(err, search.ErrIndexInvalid):
+		ix, fresh = search.NewIndex(directory), true
+	case err != nil:
+		return nil, err
+	}
+
+	stats, err := ix.Update(paths)
+	if err != nil {
+		return nil, err
+	}
+	if fresh || stats.Changed() {
+		if err := ix.Save(indexPath); err != nil {
+			return nil, err
+		}
+	}
+	return ix, nil
+}
+
+// BuildIndex creates or updates the search index at indexPath for the notes
+// in directory and reports what changed to w
+func BuildIndex(directory, indexPath string, opts IndexOptions, w io.Writer) error {
+	paths, err := notePaths(directory)
+	if err != nil {
+		return err
+	}
+
+	if opts.Rebuild {
+		if err := os.Remove(indexPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove search index: %v", err)
+		}
+	}
+
+	ix, err := openIndex(indexPath, directory, paths, true)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Indexed %d notes: %s\n", ix.Len(), indexPath)
+	return nil
+}
+
+func writeSearchResults(w io.Writer, results []search.Result, color bool) {
+	var highlight func(string) string
+	if color {
+		highlight = func(s string) string { return ansiHighlight + s + ansiReset }
+	}
+
+	for _, result := range results {
+		file := filepath.Base(result.Path)
+		if len(result.Hits) == 0 {
+			// Matched as a whole, e.g. a phrase broken across lines
+			fmt.Fprintf(w, "%s\n", file)
+			continue
+		}
+		for _, hit := range result.Hits {
+			fmt.Fprintf(w, "%s:%d: %s\n", file, hit.Line, search.Snippet(hit, snippetWidth, highlight))
+		}
+	}
+}
+
+func writeSearchJSON(w io.Writer, results []search.Result) error {
+	out := make([]searchHitJSON, 0)
+	for _, result := range results {
+		if len(result.Hits) == 0 {
+			out = append(out, searchHitJSON{Path: result.Path, File: filepath.Base(result.Path)})
+			continue
+		}
+		for _, hit := range result.Hits {
+			out = append(out, searchHitJSON{
+				Path:   result.Path,
+				File:   filepath.Base(result.Path),
+				Line:   hit.Line,
+				Text:   hit.Text,
+				Ranges: hit.Ranges,
+			})
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(out)
+}
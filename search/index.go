@@ -0,0 +1,407 @@
+package search
+
+import (
+	"bufio"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// IndexVersion is bumped whenever the on-disk index format or tokenization
+// changes; indexes with another version are rebuilt
+const IndexVersion = 1
+
+// BM25 parameters
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+var (
+	// ErrIndexNotFound is returned by LoadIndex when no index file exists
+	ErrIndexNotFound = errors.New("search index not found")
+	// ErrIndexInvalid is returned by LoadIndex when the index is corrupt,
+	// was built by another version or for another directory
+	ErrIndexInvalid = errors.New("search index is invalid")
+)
+
+// Index is a persistent inverted index over the notes below Root
+type Index struct {
+	Version int
+	Root    string
+	// Docs is indexed by document ID; removed documents have an empty Path
+	Docs []IndexedDoc
+	// Postings maps each term to the documents containing it
+	Postings map[string][]Posting
+
+	// ids maps a path relative to Root to its document ID
+	ids map[string]int
+}
+
+// IndexedDoc records what was indexed for one note
+type IndexedDoc struct {
+	// Path is relative to the index root
+	Path    string
+	ModTime int64
+	Size    int64
+	// Length is the number of tokens in the note
+	Length int
+	// Terms are the distinct terms of the note, used to remove its postings
+	Terms []string
+}
+
+// Posting is an occurrence count of a term in a document
+type Posting struct {
+	Doc  int
+	Freq int
+}
+
+// IndexStats describes the changes made by Index.Update
+type IndexStats struct {
+	Added   int
+	Updated int
+	Removed int
+}
+
+// Changed reports whether the update modified the index
+func (s IndexStats) Changed() bool {
+	return s.Added+s.Updated+s.Removed > 0
+}
+
+// Ranked is a document matching an index query with its BM25 score
+type Ranked struct {
+	Path  string
+	Score float64
+}
+
+// NewIndex returns an empty index for the notes below root
+func NewIndex(root string) *Index {
+	return &Index{
+		Version:  IndexVersion,
+		Root:     root,
+		Postings: map[string][]Posting{},
+		ids:      map[string]int{},
+	}
+}
+
+// LoadIndex reads the index stored at path for the notes below root
+func LoadIndex(path, root string) (*Index, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrIndexNotFound
+		}
+		return nil, fmt.Errorf("failed to open search index: %v", err)
+	}
+	defer file.Close()
+
+	var ix Index
+	if err := gob.NewDecoder(bufio.NewReader(file)).Decode(&ix); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIndexInvalid, err)
+	}
+	if ix.Version != IndexVersion {
+		return nil, fmt.Errorf("%w: version %d, want %d", ErrIndexInvalid, ix.Version, IndexVersion)
+	}
+	if ix.Root != root {
+		return nil, fmt.Errorf("%w: built for %s", ErrIndexInvalid, ix.Root)
+	}
+
+	if ix.Postings == nil {
+		ix.Postings = map[string][]Posting{}
+	}
+	ix.ids = make(map[string]int, len(ix.Docs))
+	for id, doc := range ix.Docs {
+		if doc.Path == "" {
+			continue
+		}
+		if _, dup := ix.ids[doc.Path]; dup {
+			return nil, fmt.Errorf("%w: duplicate document %s", ErrIndexInvalid, doc.Path)
+		}
+		ix.ids[doc.Path] = id
+	}
+	for term, postings := range ix.Postings {
+		for _, p := range postings {
+			if p.Doc < 0 || p.Doc >= len(ix.Docs) || ix.Docs[p.Doc].Path == "" {
+				return nil, fmt.Errorf("%w: dangling posting for %q", ErrIndexInvalid, term)
+			}
+		}
+	}
+
+	return &ix, nil
+}
+
+// Save writes the index to path atomically, creating its directory
+func (ix *Index) Save(path string) error {
+	ix.compact()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write search index: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	err = gob.NewEncoder(w).Encode(ix)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write search index: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write search index: %v", err)
+	}
+	return nil
+}
+
+// Len returns the number of indexed documents
+func (ix *Index) Len() int {
+	return len(ix.ids)
+}
+
+// Update brings the index in line with paths, the absolute paths of every
+// note below Root. Notes are only re-read when their modification time or
+// size changed; notes missing from paths are dropped.
+func (ix *Index) Update(paths []string) (IndexStats, error) {
+	var stats IndexStats
+	seen := make(map[string]bool, len(paths))
+
+	for _, path := range paths {
+		rel, err := filepath.Rel(ix.Root, path)
+		if err != nil {
+			return stats, fmt.Errorf("failed to index %s: %v", path, err)
+		}
+		seen[rel] = true
+
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				delete(seen, rel)
+				continue
+			}
+			return stats, fmt.Errorf("failed to index %s: %v", path, err)
+		}
+
+		id, exists := ix.ids[rel]
+		if exists {
+			doc := ix.Docs[id]
+			if doc.ModTime == info.ModTime().UnixNano() && doc.Size == info.Size() {
+				continue
+			}
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return stats, fmt.Errorf("failed to index %s: %v", path, err)
+		}
+
+		if exists {
+			ix.remove(rel)
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+		ix.add(rel, info.ModTime().UnixNano(), info.Size(), string(content))
+	}
+
+	for rel := range ix.ids {
+		if !seen[rel] {
+			ix.remove(rel)
+			stats.Removed++
+		}
+	}
+
+	return stats, nil
+}
+
+// add indexes content as a new document
+func (ix *Index) add(rel string, modTime, size int64, content string) {
+	freqs := map[string]int{}
+	tokens := Tokenize(content)
+	for _, token := range tokens {
+		freqs[token]++
+	}
+
+	terms := make([]string, 0, len(freqs))
+	for term := range freqs {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+
+	id := len(ix.Docs)
+	ix.Docs = append(ix.Docs, IndexedDoc{
+		Path:    rel,
+		ModTime: modTime,
+		Size:    size,
+		Length:  len(tokens),
+		Terms:   terms,
+	})
+	ix.ids[rel] = id
+
+	for _, term := range terms {
+		ix.Postings[term] = append(ix.Postings[term], Posting{Doc: id, Freq: freqs[term]})
+	}
+}
+
+// remove drops a document and its postings, leaving a hole in Docs
+func (ix *Index) remove(rel string) {
+	id, ok := ix.ids[rel]
+	if !ok {
+		return
+	}
+
+	for _, term := range ix.Docs[id].Terms {
+		postings := ix.Postings[term]
+		kept := postings[:0]
+		for _, p := range postings {
+			if p.Doc != id {
+				kept = append(kept, p)
+			}
+		}
+		if len(kept) == 0 {
+			delete(ix.Postings, term)
+		} else {
+			ix.Postings[term] = kept
+		}
+	}
+
+	ix.Docs[id] = IndexedDoc{}
+	delete(ix.ids, rel)
+}
+
+// compact renumbers documents to drop the holes left by removals
+func (ix *Index) compact() {
+	if len(ix.ids) == len(ix.Docs) {
+		return
+	}
+
+	remap := make([]int, len(ix.Docs))
+	docs := make([]IndexedDoc, 0, len(ix.ids))
+	for id, doc := range ix.Docs {
+		remap[id] = -1
+		if doc.Path == "" {
+			continue
+		}
+		remap[id] = len(docs)
+		ix.ids[doc.Path] = len(docs)
+		docs = append(docs, doc)
+	}
+	ix.Docs = docs
+
+	for term, postings := range ix.Postings {
+		for i := range postings {
+			postings[i].Doc = remap[postings[i].Doc]
+		}
+		ix.Postings[term] = postings
+	}
+}
+
+// Search returns the documents that may match q, ranked by BM25. A query
+// token matches every indexed term containing it, mirroring the substring
+// semantics of Query, so the result is a superset of the notes q matches
+// and callers verify candidates with q.MatchNote. Excluded terms are not
+// applied here.
+func (ix *Index) Search(q *Query) []Ranked {
+	n := float64(ix.Len())
+	if n == 0 {
+		return nil
+	}
+
+	var totalLength int
+	for _, doc := range ix.Docs {
+		totalLength += doc.Length
+	}
+	avgLength := float64(totalLength) / n
+
+	scores := map[int]float64{}
+	candidates := map[int]bool{}
+	for id, doc := range ix.Docs {
+		if doc.Path != "" {
+			candidates[id] = true
+		}
+	}
+
+	for _, token := range dedupe(q.Tokens()) {
+		freqs := map[int]int{}
+		for term, postings := range ix.Postings {
+			if !strings.Contains(term, token) {
+				continue
+			}
+			for _, p := range postings {
+				freqs[p.Doc] += p.Freq
+			}
+		}
+
+		// Documents must contain every token
+		for id := range candidates {
+			if freqs[id] == 0 {
+				delete(candidates, id)
+			}
+		}
+
+		df := float64(len(freqs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, freq := range freqs {
+			tf := float64(freq)
+			norm := 1 - bm25B + bm25B*float64(ix.Docs[id].Length)/avgLength
+			scores[id] += idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+
+	ranked := make([]Ranked, 0, len(candidates))
+	for id := range candidates {
+		ranked = append(ranked, Ranked{Path: filepath.Join(ix.Root, ix.Docs[id].Path), Score: scores[id]})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Path > ranked[j].Path
+	})
+	return ranked
+}
+
+// Tokenize splits text into lower-case runs of letters and digits
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Tokens returns the index tokens of the query's terms and phrases
+func (q *Query) Tokens() []string {
+	var tokens []string
+	for _, term := range q.Terms {
+		tokens = append(tokens, Tokenize(term)...)
+	}
+	for _, phrase := range q.Phrases {
+		tokens = append(tokens, Tokenize(phrase)...)
+	}
+	return tokens
+}
+
+func dedupe(values []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,214 @@
+package search
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeNotes writes the named notes into dir and returns their paths
+func writeNotes(t *testing.T, dir string, notes map[string]string) []string {
+	t.Helper()
+	var paths []string
+	for name, content := range notes {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func rankedPaths(ranked []Ranked) []string {
+	var paths []string
+	for _, r := range ranked {
+		paths = append(paths, filepath.Base(r.Path))
+	}
+	return paths
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := Tokenize("Deploy v1.2 to K8s-prod, ÉTÉ!")
+	expected := []string{"deploy", "v1", "2", "to", "k8s", "prod", "été"}
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("Tokenize() = %q, want %q", tokens, expected)
+	}
+}
+
+func TestIndexSearchRanking(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeNotes(t, dir, map[string]string{
+		"once.md":   "deploy happened once among many other words in a long note about things",
+		"often.md":  "deploy deploy deploy",
+		"none.md":   "nothing relevant",
+		"prefix.md": "deployment checklist",
+	})
+
+	ix := NewIndex(dir)
+	stats, err := ix.Update(paths)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats.Added != 4 || ix.Len() != 4 {
+		t.Fatalf("Expected 4 added documents, got %+v (len %d)", stats, ix.Len())
+	}
+
+	q, err := ParseQuery("deploy")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got := rankedPaths(ix.Search(q))
+	if len(got) != 3 || got[0] != "often.md" {
+		t.Errorf("Search() = %v, want often.md first and no none.md", got)
+	}
+	for _, path := range got {
+		if path == "none.md" {
+			t.Errorf("none.md should not be a candidate: %v", got)
+		}
+	}
+
+	q, err = ParseQuery(`"deploy happened" other`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := rankedPaths(ix.Search(q)); !reflect.DeepEqual(got, []string{"once.md"}) {
+		t.Errorf("Search() with all tokens required = %v, want [once.md]", got)
+	}
+}
+
+func TestIndexIncrementalUpdate(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeNotes(t, dir, map[string]string{
+		"a.md": "alpha",
+		"b.md": "beta",
+	})
+
+	ix := NewIndex(dir)
+	if _, err := ix.Update(paths); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+
+	// Unchanged notes are not re-read
+	stats, err := ix.Update(paths)
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats.Changed() {
+		t.Errorf("Update without changes reported %+v", stats)
+	}
+
+	// Change a.md, delete b.md, add c.md
+	aPath := filepath.Join(dir, "a.md")
+	if err := os.WriteFile(aPath, []byte("gamma gamma"), 0644); err != nil {
+		t.Fatalf("Failed to update a.md: %v", err)
+	}
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(aPath, later, later); err != nil {
+		t.Fatalf("Failed to set mtime: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "b.md")); err != nil {
+		t.Fatalf("Failed to remove b.md: %v", err)
+	}
+	cPaths := writeNotes(t, dir, map[string]string{"c.md": "alpha"})
+
+	stats, err = ix.Update([]string{aPath, cPaths[0]})
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if stats != (IndexStats{Added: 1, Updated: 1, Removed: 1}) {
+		t.Errorf("Update stats = %+v", stats)
+	}
+
+	for query, expected := range map[string][]string{
+		"alpha": {"c.md"},
+		"gamma": {"a.md"},
+		"beta":  nil,
+	} {
+		q, err := ParseQuery(query)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := rankedPaths(ix.Search(q)); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Search(%q) = %v, want %v", query, got, expected)
+		}
+	}
+}
+
+func TestIndexSaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	paths := writeNotes(t, dir, map[string]string{
+		"a.md": "alpha",
+		"b.md": "beta",
+		"c.md": "alpha beta",
+	})
+	indexPath := filepath.Join(t.TempDir(), "index", "notes.gob")
+
+	ix := NewIndex(dir)
+	if _, err := ix.Update(paths); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	// Leave a hole in the document list so Save has to compact it
+	if _, err := ix.Update([]string{filepath.Join(dir, "b.md"), filepath.Join(dir, "c.md")}); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if err := ix.Save(indexPath); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := LoadIndex(indexPath, dir)
+	if err != nil {
+		t.Fatalf("LoadIndex failed: %v", err)
+	}
+	if loaded.Len() != 2 {
+		t.Errorf("Loaded index has %d documents, want 2", loaded.Len())
+	}
+
+	q, err := ParseQuery("alpha")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := rankedPaths(loaded.Search(q)); !reflect.DeepEqual(got, []string{"c.md"}) {
+		t.Errorf("Search after load = %v, want [c.md]", got)
+	}
+}
+
+func TestLoadIndexErrors(t *testing.T) {
+	dir := t.TempDir()
+	indexDir := t.TempDir()
+
+	if _, err := LoadIndex(filepath.Join(indexDir, "missing.gob"), dir); !errors.Is(err, ErrIndexNotFound) {
+		t.Errorf("Missing index: got %v, want ErrIndexNotFound", err)
+	}
+
+	corrupt := filepath.Join(indexDir, "corrupt.gob")
+	if err := os.WriteFile(corrupt, []byte("not a gob stream"), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt index: %v", err)
+	}
+	if _, err := LoadIndex(corrupt, dir); !errors.Is(err, ErrIndexInvalid) {
+		t.Errorf("Corrupt index: got %v, want ErrIndexInvalid", err)
+	}
+
+	old := NewIndex(dir)
+	old.Version = IndexVersion - 1
+	oldPath := filepath.Join(indexDir, "old.gob")
+	if err := old.Save(oldPath); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := LoadIndex(oldPath, dir); !errors.Is(err, ErrIndexInvalid) {
+		t.Errorf("Old index: got %v, want ErrIndexInvalid", err)
+	}
+
+	other := NewIndex(filepath.Join(dir, "other"))
+	otherPath := filepath.Join(indexDir, "other.gob")
+	if err := other.Save(otherPath); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := LoadIndex(otherPath, dir); !errors.Is(err, ErrIndexInvalid) {
+		t.Errorf("Index for another root: got %v, want ErrIndexInvalid", err)
+	}
+}
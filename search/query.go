@@ -0,0 +1,181 @@
+package search
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Matcher decides which notes match a query and where in a line they match
+type Matcher interface {
+	// MatchNote reports whether the full content of a note matches
+	MatchNote(content string) bool
+	// MatchLine returns the sorted, non-overlapping [start, end) byte ranges
+	// matched within line
+	MatchLine(line string) [][]int
+}
+
+// Query is a parsed search query. A note matches when it contains every
+// term and phrase and none of the exclusions; all comparisons ignore case.
+type Query struct {
+	Terms    []string
+	Phrases  []string
+	Excludes []string
+
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// ParseQuery parses a query such as `deploy "error budget" -staging`:
+// bare words are terms, double-quoted text is a phrase and a leading "-"
+// excludes the following term or phrase.
+func ParseQuery(s string) (*Query, error) {
+	q := &Query{}
+
+	runes := []rune(s)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		exclude := false
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			exclude = true
+			i++
+		}
+
+		var token string
+		phrase := false
+		if runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated quote in query %q", s)
+			}
+			token = strings.Join(strings.Fields(string(runes[i+1:end])), " ")
+			phrase = true
+			i = end + 1
+		} else {
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) {
+				end++
+			}
+			token = string(runes[i:end])
+			i = end
+		}
+
+		if token == "" {
+			continue
+		}
+
+		switch {
+		case exclude:
+			q.Excludes = append(q.Excludes, token)
+		case phrase:
+			q.Phrases = append(q.Phrases, token)
+		default:
+			q.Terms = append(q.Terms, token)
+		}
+	}
+
+	if len(q.Terms) == 0 && len(q.Phrases) == 0 {
+		return nil, fmt.Errorf("query %q has no search terms", s)
+	}
+
+	for _, term := range append(append([]string{}, q.Terms...), q.Phrases...) {
+		q.include = append(q.include, literalPattern(term))
+	}
+	for _, term := range q.Excludes {
+		q.exclude = append(q.exclude, literalPattern(term))
+	}
+
+	return q, nil
+}
+
+// literalPattern matches text case-insensitively, letting any run of
+// whitespace stand in for the spaces of a phrase
+func literalPattern(text string) *regexp.Regexp {
+	words := strings.Fields(text)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(words, `\s+`))
+}
+
+func (q *Query) MatchNote(content string) bool {
+	for _, re := range q.include {
+		if !re.MatchString(content) {
+			return false
+		}
+	}
+	for _, re := range q.exclude {
+		if re.MatchString(content) {
+			return false
+		}
+	}
+	return true
+}
+
+func (q *Query) MatchLine(line string) [][]int {
+	var ranges [][]int
+	for _, re := range q.include {
+		ranges = append(ranges, re.FindAllStringIndex(line, -1)...)
+	}
+	return mergeRanges(ranges)
+}
+
+// Regexp matches notes with a regular expression, ignoring case
+type Regexp struct {
+	re *regexp.Regexp
+}
+
+// NewRegexp compiles pattern into a case-insensitive Matcher
+func NewRegexp(pattern string) (*Regexp, error) {
+	re, err := regexp.Compile(`(?i)` + pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression: %v", err)
+	}
+	return &Regexp{re: re}, nil
+}
+
+func (r *Regexp) MatchNote(content string) bool {
+	return r.re.MatchString(content)
+}
+
+func (r *Regexp) MatchLine(line string) [][]int {
+	var ranges [][]int
+	for _, loc := range r.re.FindAllStringIndex(line, -1) {
+		// Empty matches cannot be highlighted and would flag every line
+		if loc[1] > loc[0] {
+			ranges = append(ranges, loc)
+		}
+	}
+	return ranges
+}
+
+// mergeRanges sorts ranges and merges overlapping or adjacent ones
+func mergeRanges(ranges [][]int) [][]int {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+
+	merged := [][]int{{ranges[0][0], ranges[0][1]}}
+	for _, r := range ranges[1:] {
+		last := merged[len(merged)-1]
+		if r[0] <= last[1] {
+			if r[1] > last[1] {
+				last[1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, []int{r[0], r[1]})
+	}
+	return merged
+}
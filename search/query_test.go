@@ -0,0 +1,151 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		expectedTerms    []string
+		expectedPhrases  []string
+		expectedExcludes []string
+		expectError      bool
+	}{
+		{
+			name:          "single term",
+			query:         "deploy",
+			expectedTerms: []string{"deploy"},
+		},
+		{
+			name:             "terms, phrase and exclusion",
+			query:            `deploy "error  budget" -staging`,
+			expectedTerms:    []string{"deploy"},
+			expectedPhrases:  []string{"error budget"},
+			expectedExcludes: []string{"staging"},
+		},
+		{
+			name:             "excluded phrase",
+			query:            `outage -"false alarm"`,
+			expectedTerms:    []string{"outage"},
+			expectedExcludes: []string{"false alarm"},
+		},
+		{
+			name:          "lone dash is a term",
+			query:         "a - b",
+			expectedTerms: []string{"a", "-", "b"},
+		},
+		{
+			name:        "only exclusions",
+			query:       "-staging",
+			expectError: true,
+		},
+		{
+			name:        "empty query",
+			query:       "   ",
+			expectError: true,
+		},
+		{
+			name:        "unterminated quote",
+			query:       `"error budget`,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseQuery(%q) expected error", tt.query)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) unexpected error: %v", tt.query, err)
+			}
+
+			if !reflect.DeepEqual(q.Terms, tt.expectedTerms) {
+				t.Errorf("Terms = %q, want %q", q.Terms, tt.expectedTerms)
+			}
+			if !reflect.DeepEqual(q.Phrases, tt.expectedPhrases) {
+				t.Errorf("Phrases = %q, want %q", q.Phrases, tt.expectedPhrases)
+			}
+			if !reflect.DeepEqual(q.Excludes, tt.expectedExcludes) {
+				t.Errorf("Excludes = %q, want %q", q.Excludes, tt.expectedExcludes)
+			}
+		})
+	}
+}
+
+func TestQueryMatchNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		content  string
+		expected bool
+	}{
+		{name: "case-insensitive term", query: "deploy", content: "DEPLOY went fine", expected: true},
+		{name: "all terms required", query: "deploy rollback", content: "deploy went fine", expected: false},
+		{name: "terms on different lines", query: "deploy rollback", content: "deploy\nrollback", expected: true},
+		{name: "phrase across whitespace", query: `"error budget"`, content: "the error\n  budget is gone", expected: true},
+		{name: "phrase words apart", query: `"error budget"`, content: "error in the budget", expected: false},
+		{name: "exclusion", query: "deploy -staging", content: "deploy to Staging", expected: false},
+		{name: "regex characters are literal", query: "a.b", content: "axb", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) unexpected error: %v", tt.query, err)
+			}
+			if got := q.MatchNote(tt.content); got != tt.expected {
+				t.Errorf("MatchNote(%q) = %v, want %v", tt.content, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestQueryMatchLineMergesRanges(t *testing.T) {
+	q, err := ParseQuery(`error "error budget" budget`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ranges := q.MatchLine("the error budget, the budget")
+	expected := [][]int{{4, 16}, {22, 28}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("MatchLine() = %v, want %v", ranges, expected)
+	}
+}
+
+func TestRegexp(t *testing.T) {
+	m, err := NewRegexp(`err(or)?\s+\d+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if !m.MatchNote("line\nERROR 500\n") {
+		t.Error("Regexp should match case-insensitively")
+	}
+	if ranges := m.MatchLine("got err 42 and error 7"); !reflect.DeepEqual(ranges, [][]int{{4, 10}, {15, 22}}) {
+		t.Errorf("MatchLine() = %v", ranges)
+	}
+
+	empty, err := NewRegexp(`x*`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ranges := empty.MatchLine("abc"); len(ranges) != 0 {
+		t.Errorf("Empty matches should be ignored, got %v", ranges)
+	}
+
+	if _, err := NewRegexp(`(`); err == nil {
+		t.Error("Expected error for invalid regular expression")
+	}
+}
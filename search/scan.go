@@ -0,0 +1,102 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Hit is a matching line within a note
+type Hit struct {
+	// Line is the 1-based line number
+	Line int
+	Text string
+	// Ranges are the [start, end) byte offsets of the matches within Text
+	Ranges [][]int
+}
+
+// Result holds the matching lines of one note
+type Result struct {
+	Path string
+	Hits []Hit
+}
+
+// Scan reads every file in paths with at most workers concurrent readers
+// and returns the matching notes in the order of paths. A workers value
+// below 1 uses one worker per CPU.
+func Scan(paths []string, m Matcher, workers int) ([]Result, error) {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+
+	type outcome struct {
+		result  Result
+		matched bool
+		err     error
+	}
+	outcomes := make([]outcome, len(paths))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result, matched, err := scanFile(paths[i], m)
+				outcomes[i] = outcome{result: result, matched: matched, err: err}
+			}
+		}()
+	}
+
+	for i := range paths {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var results []Result
+	for _, o := range outcomes {
+		if o.err != nil {
+			return nil, o.err
+		}
+		if o.matched {
+			results = append(results, o.result)
+		}
+	}
+	return results, nil
+}
+
+// scanFile matches a single note against m
+func scanFile(path string, m Matcher) (Result, bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Deleted while searching
+			return Result{}, false, nil
+		}
+		return Result{}, false, fmt.Errorf("failed to read %s: %v", path, err)
+	}
+
+	result, matched := MatchContent(path, string(data), m)
+	return result, matched, nil
+}
+
+// MatchContent matches the content of the note at path against m and
+// collects the matching lines
+func MatchContent(path, content string, m Matcher) (Result, bool) {
+	if !m.MatchNote(content) {
+		return Result{}, false
+	}
+
+	result := Result{Path: path}
+	for i, line := range strings.Split(content, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if ranges := m.MatchLine(line); len(ranges) > 0 {
+			result.Hits = append(result.Hits, Hit{Line: i + 1, Text: line, Ranges: ranges})
+		}
+	}
+	return result, true
+}
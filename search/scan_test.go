@@ -0,0 +1,76 @@
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.md": "deploy\nnothing here\nDeploy again\n",
+		"b.md": "no match\n",
+		"c.md": "deploy to staging\n",
+	}
+	var paths []string
+	for _, name := range []string{"a.md", "b.md", "c.md", "missing.md"} {
+		path := filepath.Join(dir, name)
+		if content, ok := files[name]; ok {
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write %s: %v", name, err)
+			}
+		}
+		paths = append(paths, path)
+	}
+
+	q, err := ParseQuery("deploy -staging")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results, err := Scan(paths, q, 2)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if len(results) != 1 || results[0].Path != paths[0] {
+		t.Fatalf("Expected only a.md to match, got %+v", results)
+	}
+
+	hits := results[0].Hits
+	if len(hits) != 2 || hits[0].Line != 1 || hits[1].Line != 3 {
+		t.Errorf("Unexpected hits: %+v", hits)
+	}
+}
+
+func TestScanKeepsPathOrder(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i := 0; i < 50; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("%02d.md", i))
+		if err := os.WriteFile(path, []byte("match"), 0644); err != nil {
+			t.Fatalf("Failed to write note: %v", err)
+		}
+		paths = append(paths, path)
+	}
+
+	q, err := ParseQuery("match")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	results, err := Scan(paths, q, 4)
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if len(results) != len(paths) {
+		t.Fatalf("Expected %d results, got %d", len(paths), len(results))
+	}
+	for i, result := range results {
+		if result.Path != paths[i] {
+			t.Fatalf("Result %d = %s, want %s", i, result.Path, paths[i])
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Snippet returns the part of hit.Text around its first match, at most
+// width bytes of context plus the matches, with "..." marking cut text.
+// Each match is passed through highlight, which may be nil.
+func Snippet(hit Hit, width int, highlight func(string) string) string {
+	text := hit.Text
+	ranges := hit.Ranges
+
+	start, end := 0, len(text)
+	if len(ranges) > 0 && width > 0 && len(text) > width {
+		first := ranges[0]
+		before := (width - (first[1] - first[0])) / 2
+		if before < 0 {
+			before = 0
+		}
+		start = alignRune(text, first[0]-before)
+		end = alignRune(text, start+width)
+		if end < first[1] {
+			end = first[1]
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("...")
+	}
+
+	pos := start
+	for _, r := range ranges {
+		if r[1] <= start || r[0] >= end {
+			continue
+		}
+		from, to := max(r[0], start), min(r[1], end)
+		b.WriteString(text[pos:from])
+		if highlight != nil {
+			b.WriteString(highlight(text[from:to]))
+		} else {
+			b.WriteString(text[from:to])
+		}
+		pos = to
+	}
+	b.WriteString(text[pos:end])
+
+	if end < len(text) {
+		b.WriteString("...")
+	}
+	return b.String()
+}
+
+// alignRune clamps i to text and moves it back to the start of a rune
+func alignRune(text string, i int) int {
+	if i <= 0 {
+		return 0
+	}
+	if i >= len(text) {
+		return len(text)
+	}
+	for i > 0 && !utf8.RuneStart(text[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,53 @@
+package search
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSnippet(t *testing.T) {
+	brackets := func(s string) string { return "[" + s + "]" }
+
+	tests := []struct {
+		name      string
+		hit       Hit
+		width     int
+		highlight func(string) string
+		expected  string
+	}{
+		{
+			name:     "short line unchanged",
+			hit:      Hit{Text: "deploy went fine", Ranges: [][]int{{0, 6}}},
+			width:    100,
+			expected: "deploy went fine",
+		},
+		{
+			name:      "matches highlighted",
+			hit:       Hit{Text: "deploy and deploy", Ranges: [][]int{{0, 6}, {11, 17}}},
+			width:     100,
+			highlight: brackets,
+			expected:  "[deploy] and [deploy]",
+		},
+		{
+			name:      "long line trimmed around match",
+			hit:       Hit{Text: strings.Repeat("a", 50) + "match" + strings.Repeat("b", 50), Ranges: [][]int{{50, 55}}},
+			width:     15,
+			highlight: brackets,
+			expected:  "..." + "aaaaa" + "[match]" + "bbbbb" + "...",
+		},
+		{
+			name:     "match at start of long line",
+			hit:      Hit{Text: "match" + strings.Repeat("b", 50), Ranges: [][]int{{0, 5}}},
+			width:    10,
+			expected: "matchbbbbb...",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Snippet(tt.hit, tt.width, tt.highlight); got != tt.expected {
+				t.Errorf("Snippet() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"scratch-note/search"
+)
+
+// writeTestNoteContent writes content to the named note in dir
+func writeTestNoteContent(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory for %s: %v", name, err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test note %s: %v", name, err)
+	}
+	return path
+}
+
+func TestSearchNotes(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-08-15_100000_old.md", "deploy failed\nerror budget exhausted\n")
+	writeTestNoteContent(t, dir, "2025-08-16_100000_new.md", "Deploy succeeded\n")
+	writeTestNoteContent(t, dir, "2025-08-17_100000_staging.md", "deploy to staging\n")
+	writeTestNoteContent(t, dir, "notes.txt", "deploy\n")
+
+	var buf bytes.Buffer
+	err := SearchNotes(dir, "", SearchOptions{Query: "deploy -staging"}, &buf)
+	if err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+
+	expected := "2025-08-16_100000_new.md:1: Deploy succeeded\n" +
+		"2025-08-15_100000_old.md:1: deploy failed\n"
+	if buf.String() != expected {
+		t.Errorf("SearchNotes output:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+func TestSearchNotesHighlight(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-08-15_100000.md", "the error budget\n")
+
+	var buf bytes.Buffer
+	err := SearchNotes(dir, "", SearchOptions{Query: `"error budget"`, Color: true}, &buf)
+	if err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), ansiHighlight+"error budget"+ansiReset) {
+		t.Errorf("Match should be highlighted, got %q", buf.String())
+	}
+}
+
+func TestSearchNotesJSONAndRegex(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestNoteContent(t, dir, "2025-08-15_100000_incident.md", "status\nHTTP 503 from api\n")
+
+	var buf bytes.Buffer
+	err := SearchNotes(dir, "", SearchOptions{Query: `http \d{3}`, Regex: true, JSON: true}, &buf)
+	if err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+
+	var hits []searchHitJSON
+	if err := json.Unmarshal(buf.Bytes(), &hits); err != nil {
+		t.Fatalf("Output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(hits) != 1 {
+		t.Fatalf("Expected 1 hit, got %d", len(hits))
+	}
+	if hits[0].Path != path || hits[0].Line != 2 || hits[0].Text != "HTTP 503 from api" {
+		t.Errorf("Unexpected hit: %+v", hits[0])
+	}
+}
+
+func TestSearchNotesInvalidQuery(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := SearchNotes(dir, "", SearchOptions{Query: "-only"}, &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for query without terms")
+	}
+	if err := SearchNotes(dir, "", SearchOptions{Query: "(", Regex: true}, &bytes.Buffer{}); err == nil {
+		t.Error("Expected error for invalid regular expression")
+	}
+}
+
+func TestParseArgsSearch(t *testing.T) {
+	cmd, err := ParseArgs([]string{"scratch-note", "search", "--json", "error", "budget", "--", "-staging"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if cmd.Type != CommandTypeSearch || cmd.Search.Query != "error budget -staging" || !cmd.Search.JSON {
+		t.Errorf("Unexpected command: %+v", cmd)
+	}
+
+	if _, err := ParseArgs([]string{"scratch-note", "search"}); err == nil {
+		t.Error("Expected error for missing query")
+	}
+}
+
+func TestSearchNotesWithIndex(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+	writeTestNoteContent(t, dir, "2025-08-17_100000_newest.md", "deploy once in a much longer note with plenty of other words\n")
+	writeTestNoteContent(t, dir, "2025-08-15_100000_oldest.md", "deploy deploy deploy\n")
+
+	if err := BuildIndex(dir, indexPath, IndexOptions{}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("BuildIndex failed: %v", err)
+	}
+
+	// A note added after indexing is picked up incrementally
+	writeTestNoteContent(t, dir, "2025-08-18_100000_added.md", "unrelated\n")
+	writeTestNoteContent(t, dir, "2025-08-19_100000_deploy.md", "deploy deploy deploy deploy\n")
+
+	var buf bytes.Buffer
+	if err := SearchNotes(dir, indexPath, SearchOptions{Query: "deploy"}, &buf); err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 hits, got:\n%s", buf.String())
+	}
+	if !strings.HasPrefix(lines[0], "2025-08-19_100000_deploy.md") || !strings.HasPrefix(lines[2], "2025-08-17_100000_newest.md") {
+		t.Errorf("Hits should be ranked by relevance, got:\n%s", buf.String())
+	}
+}
+
+func TestSearchNotesRebuildsCorruptIndex(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+	writeTestNoteContent(t, dir, "2025-08-15_100000.md", "deploy\n")
+
+	if err := os.WriteFile(indexPath, []byte("garbage"), 0644); err != nil {
+		t.Fatalf("Failed to write corrupt index: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := SearchNotes(dir, indexPath, SearchOptions{Query: "deploy"}, &buf); err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+	if !strings.Contains(buf.String(), "2025-08-15_100000.md:1:") {
+		t.Errorf("Expected a hit after rebuilding the index, got:\n%s", buf.String())
+	}
+
+	if _, err := search.LoadIndex(indexPath, dir); err != nil {
+		t.Errorf("Index should have been rebuilt and saved: %v", err)
+	}
+}
+
+func TestSearchNotesWithoutIndex(t *testing.T) {
+	dir := t.TempDir()
+	indexPath := filepath.Join(t.TempDir(), "index.gob")
+	writeTestNoteContent(t, dir, "2025-08-15_100000.md", "deploy\n")
+
+	if err := SearchNotes(dir, indexPath, SearchOptions{Query: "deploy"}, &bytes.Buffer{}); err != nil {
+		t.Fatalf("SearchNotes failed: %v", err)
+	}
+
+	if _, err := os.Stat(indexPath); !os.IsNotExist(err) {
+		t.Error("Searching without an index should not create one")
+	}
+}
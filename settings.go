@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"scratch-note/config"
+)
+
+// ShowConfig writes the effective value of every configuration key to w,
+// and with origin also the layer and file, variable or flag it came from.
+// Values of the selected notebook are shown as the values of their keys.
+func ShowConfig(src *config.Source, origin bool, w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	if origin {
+		fmt.Fprintln(tw, "KEY\tVALUE\tORIGIN")
+	} else {
+		fmt.Fprintln(tw, "KEY\tVALUE")
+	}
+	for _, key := range config.Keys() {
+		value := src.Value(key)
+		if value == "" {
+			value = `""`
+		}
+		if origin {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", key, value, src.Origin(key))
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\n", key, value)
+		}
+	}
+	return tw.Flush()
+}
+
+// ListNotebooks writes the notebooks of cfg and their directories to w,
+// marking the selected one with *
+func ListNotebooks(cfg *config.Config, w io.Writer) error {
+	if len(cfg.Notebooks) == 0 {
+		fmt.Fprintln(w, "No notebooks configured")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, " \tNOTEBOOK\tDIRECTORY")
+	for _, name := range cfg.NotebookNames() {
+		mark := " "
+		if name == cfg.Notebook {
+			mark = "*"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", mark, name, cfg.Notebooks[name].ScratchNoteDir)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"scratch-note/config"
+)
+
+func TestShowConfig(t *testing.T) {
+	userPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(userPath, []byte("scratch_note_dir: /srv/notes\nlayout: monthly\n"), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	src, problems, err := config.LoadLayers(config.Layers{
+		UserPath:  userPath,
+		Overrides: []config.Override{{Key: "editor", Value: "nano", Flag: "--editor"}},
+	})
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("LoadLayers() unexpected problems: %v %v", problems, err)
+	}
+
+	var buf bytes.Buffer
+	if err := ShowConfig(src, true, &buf); err != nil {
+		t.Fatalf("ShowConfig() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != len(config.Keys())+1 {
+		t.Fatalf("ShowConfig() wrote %d lines, want a header and one per key:\n%s", len(lines), buf.String())
+	}
+	for _, expected := range [][]string{
+		{"KEY", "VALUE", "ORIGIN"},
+		{"scratch_note_dir", "/srv/notes", "user", userPath + ":1"},
+		{"editor", "nano", "flag", "--editor"},
+		{"keep_empty_notes", "false", "default"},
+		{"filename_template", `""`, "default"},
+		{"layout", "monthly", "user", userPath + ":2"},
+	} {
+		found := false
+		for _, line := range lines {
+			if strings.Join(strings.Fields(line), " ") == strings.Join(expected, " ") {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("ShowConfig() should have a line %q:\n%s", strings.Join(expected, " "), buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := ShowConfig(src, false, &buf); err != nil {
+		t.Fatalf("ShowConfig() unexpected error: %v", err)
+	}
+	if strings.Contains(buf.String(), "ORIGIN") || strings.Contains(buf.String(), "--editor") {
+		t.Errorf("ShowConfig() without origin should not show origins:\n%s", buf.String())
+	}
+}
+
+func TestListNotebooks(t *testing.T) {
+	var buf bytes.Buffer
+	if err := ListNotebooks(&config.Config{}, &buf); err != nil || buf.String() != "No notebooks configured\n" {
+		t.Errorf("ListNotebooks() = %q, %v", buf.String(), err)
+	}
+
+	cfg := &config.Config{Notebook: "work", Notebooks: map[string]config.Notebook{
+		"work":     {ScratchNoteDir: "~/work-notes"},
+		"personal": {ScratchNoteDir: "~/notes"},
+	}}
+	buf.Reset()
+	if err := ListNotebooks(cfg, &buf); err != nil {
+		t.Fatalf("ListNotebooks() unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || strings.Join(strings.Fields(lines[1]), " ") != "personal ~/notes" || strings.Join(strings.Fields(lines[2]), " ") != "* work ~/work-notes" {
+		t.Errorf("ListNotebooks() =\n%s", buf.String())
+	}
+}
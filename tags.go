@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"scratch-note/notes"
+)
+
+// Actions of the tag command
+const (
+	TagActionAdd    = "add"
+	TagActionRemove = "rm"
+)
+
+// TagOptions holds the options of the tag command
+type TagOptions struct {
+	// Action is TagActionAdd or TagActionRemove
+	Action string
+	// Ref is a path, list index or title fragment of the note
+	Ref string
+	// Tags are added or removed, normalized
+	Tags []string
+}
+
+// tagFlag is a flag.Value collecting normalized tags, e.g. -t infra
+type tagFlag []string
+
+func (f *tagFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *tagFlag) Set(value string) error {
+	tag := notes.NormalizeTag(value)
+	if err := notes.ValidateTag(tag); err != nil {
+		return err
+	}
+	*f = append(*f, tag)
+	return nil
+}
+
+// tagFilterFlag is a flag.Value collecting tag filters, where !tag excludes
+// notes having the tag
+type tagFilterFlag []string
+
+func (f *tagFilterFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *tagFilterFlag) Set(value string) error {
+	negated := strings.HasPrefix(value, "!")
+	tag := notes.NormalizeTag(strings.TrimPrefix(value, "!"))
+	if err := notes.ValidateTag(tag); err != nil {
+		return err
+	}
+	if negated {
+		tag = "!" + tag
+	}
+	*f = append(*f, tag)
+	return nil
+}
+
+// parseTags normalizes and validates tags given as arguments
+func parseTags(args []string) ([]string, error) {
+	var tags tagFlag
+	for _, arg := range args {
+		if err := tags.Set(arg); err != nil {
+			return nil, err
+		}
+	}
+	return tags, nil
+}
+
+// readNoteTags returns the tags of the note at path, from its frontmatter
+// and its #hashtags. A note with broken frontmatter only has its hashtags.
+func readNoteTags(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read note: %v", err)
+	}
+	doc, err := notes.Parse(content)
+	if err != nil {
+		return notes.Hashtags(content), nil
+	}
+	return doc.Tags(), nil
+}
+
+// matchTags reports whether tags satisfy every filter: a plain tag must be
+// present and a !tag must be absent
+func matchTags(tags, filters []string) bool {
+	has := map[string]bool{}
+	for _, tag := range tags {
+		has[tag] = true
+	}
+	for _, filter := range filters {
+		if excluded, ok := strings.CutPrefix(filter, "!"); ok {
+			if has[excluded] {
+				return false
+			}
+		} else if !has[filter] {
+			return false
+		}
+	}
+	return true
+}
+
+// TagNote adds tags to or removes them from the frontmatter of the note
+// selected by opts in directory, writing a summary to w. Removed tags that
+// still appear as #hashtags in the body are reported. It returns the path of
+// the note.
+func TagNote(directory string, opts TagOptions, w io.Writer) (string, error) {
+	path, err := ResolveUniqueNote(directory, opts.Ref)
+	if err != nil {
+		return "", err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read note: %v", err)
+	}
+	doc, err := notes.Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	tags := doc.FrontmatterTags()
+	switch opts.Action {
+	case TagActionAdd:
+		for _, tag := range opts.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	case TagActionRemove:
+		var kept []string
+		for _, tag := range tags {
+			if !slices.Contains(opts.Tags, tag) {
+				kept = append(kept, tag)
+			}
+		}
+		tags = kept
+	default:
+		return "", fmt.Errorf("unknown tag action: %s", opts.Action)
+	}
+
+	if err := doc.SetTags(tags); err != nil {
+		return "", err
+	}
+	updated, err := doc.Bytes()
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, updated, 0644); err != nil {
+		return "", fmt.Errorf("failed to write note: %v", err)
+	}
+
+	if opts.Action == TagActionRemove {
+		hashtags := notes.Hashtags(doc.Body)
+		for _, tag := range opts.Tags {
+			if slices.Contains(hashtags, tag) {
+				fmt.Fprintf(w, "Note: #%s still appears in the body\n", tag)
+			}
+		}
+	}
+	return path, nil
+}
+
+// TagCount is the number of notes having a tag
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// CountTags returns the tags of the notes in directory with the number of
+// notes having each, most used first
+func CountTags(directory string) ([]TagCount, error) {
+	entries, err := collectNotes(directory)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, entry := range entries {
+		tags, err := readNoteTags(entry.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range tags {
+			counts[tag]++
+		}
+	}
+
+	result := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		result = append(result, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Tag < result[j].Tag
+	})
+	return result, nil
+}
+
+// ListTags writes the tags of the notes in directory and their counts to w
+func ListTags(directory string, w io.Writer) error {
+	counts, err := CountTags(directory)
+	if err != nil {
+		return err
+	}
+	if len(counts) == 0 {
+		fmt.Fprintln(w, "No tags found")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TAG\tNOTES")
+	for _, c := range counts {
+		fmt.Fprintf(tw, "%s\t%d\n", c.Tag, c.Count)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTagNote(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestNoteContent(t, dir, "2025-08-16_100000_disk-full.md", "---\ntitle: Disk full\ntags: [bug]\n---\nSee #infra\n")
+
+	var out bytes.Buffer
+	if _, err := TagNote(dir, TagOptions{Action: TagActionAdd, Ref: "disk", Tags: []string{"infra", "bug", "urgent"}}, &out); err != nil {
+		t.Fatalf("TagNote(add) unexpected error: %v", err)
+	}
+	content, _ := os.ReadFile(path)
+	if string(content) != "---\ntitle: Disk full\ntags:\n  - bug\n  - infra\n  - urgent\n---\nSee #infra\n" {
+		t.Errorf("TagNote(add) content = %q", content)
+	}
+
+	if _, err := TagNote(dir, TagOptions{Action: TagActionRemove, Ref: "disk", Tags: []string{"infra", "bug"}}, &out); err != nil {
+		t.Fatalf("TagNote(rm) unexpected error: %v", err)
+	}
+	content, _ = os.ReadFile(path)
+	if string(content) != "---\ntitle: Disk full\ntags:\n  - urgent\n---\nSee #infra\n" {
+		t.Errorf("TagNote(rm) content = %q", content)
+	}
+	if !strings.Contains(out.String(), "#infra still appears in the body") {
+		t.Errorf("TagNote(rm) should report the remaining hashtag, got %q", out.String())
+	}
+
+	// A note without frontmatter gets a block
+	plain := writeTestNoteContent(t, dir, "2025-08-17_100000_idea.md", "just text\n")
+	if _, err := TagNote(dir, TagOptions{Action: TagActionAdd, Ref: "idea", Tags: []string{"idea"}}, &out); err != nil {
+		t.Fatalf("TagNote(add) unexpected error: %v", err)
+	}
+	content, _ = os.ReadFile(plain)
+	if string(content) != "---\ntags:\n  - idea\n---\njust text\n" {
+		t.Errorf("TagNote(add) content = %q", content)
+	}
+}
+
+func TestCountTags(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-08-15_100000_a.md", "---\ntags: [infra, bug]\n---\n")
+	writeTestNoteContent(t, dir, "2025-08-16_100000_b.md", "#infra #done\n")
+	writeTestNoteContent(t, dir, "2025-08-17_100000_c.md", "---\ntags: [infra]\n---\n#infra again\n")
+	writeTestNoteContent(t, dir, "notes.txt", "#ignored\n")
+
+	counts, err := CountTags(dir)
+	if err != nil {
+		t.Fatalf("CountTags() unexpected error: %v", err)
+	}
+	expected := []TagCount{{"infra", 3}, {"bug", 1}, {"done", 1}}
+	if !reflect.DeepEqual(counts, expected) {
+		t.Errorf("CountTags() = %v, want %v", counts, expected)
+	}
+
+	var buf bytes.Buffer
+	if err := ListTags(dir, &buf); err != nil {
+		t.Fatalf("ListTags() unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 || strings.Join(strings.Fields(lines[1]), " ") != "infra 3" {
+		t.Errorf("ListTags() =\n%s", buf.String())
+	}
+}
+
+func TestListNotesByTag(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-08-15_100000_a.md", "---\ntags: [infra, done]\n---\n")
+	writeTestNoteContent(t, dir, "2025-08-16_100000_b.md", "#infra\n")
+	writeTestNoteContent(t, dir, "2025-08-17_100000_c.md", "#bug\n")
+
+	tests := []struct {
+		name     string
+		tags     []string
+		expected []string
+	}{
+		{"single tag", []string{"infra"}, []string{"2025-08-16_100000_b.md", "2025-08-15_100000_a.md"}},
+		{"excluded tag", []string{"infra", "!done"}, []string{"2025-08-16_100000_b.md"}},
+		{"only exclusions", []string{"!infra"}, []string{"2025-08-17_100000_c.md"}},
+		{"no match", []string{"infra", "bug"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := defaultListOptions()
+			opts.Tags = tt.tags
+			var buf bytes.Buffer
+			if err := ListNotes(dir, opts, &buf); err != nil {
+				t.Fatalf("ListNotes() unexpected error: %v", err)
+			}
+			var got []string
+			for _, line := range strings.Fields(buf.String()) {
+				got = append(got, filepath.Base(line))
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("ListNotes(--tag %v) = %v, want %v", tt.tags, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParseArgsTag(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    Command
+		expectError bool
+	}{
+		{
+			name:     "new with tags",
+			args:     []string{"scratch-note", "-t", "Bug", "--tag", "#infra", "disk full"},
+			expected: Command{Type: CommandTypeCreate, Title: "disk full", Create: CreateOptions{Tags: []string{"bug", "infra"}}},
+		},
+		{
+			name:     "tag add",
+			args:     []string{"scratch-note", "tag", "add", "disk", "infra", "urgent"},
+			expected: Command{Type: CommandTypeTag, Tag: TagOptions{Action: TagActionAdd, Ref: "disk", Tags: []string{"infra", "urgent"}}},
+		},
+		{
+			name:     "tag rm",
+			args:     []string{"scratch-note", "tag", "rm", "1", "infra"},
+			expected: Command{Type: CommandTypeTag, Tag: TagOptions{Action: TagActionRemove, Ref: "1", Tags: []string{"infra"}}},
+		},
+		{
+			name:     "tags",
+			args:     []string{"scratch-note", "tags"},
+			expected: Command{Type: CommandTypeTags},
+		},
+		{name: "invalid tag", args: []string{"scratch-note", "-t", "two words", "x"}, expectError: true},
+		{name: "tag without tags", args: []string{"scratch-note", "tag", "add", "disk"}, expectError: true},
+		{name: "unknown tag action", args: []string{"scratch-note", "tag", "set", "disk", "x"}, expectError: true},
+		{name: "list with invalid filter", args: []string{"scratch-note", "list", "--tag", "!"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseArgs(tt.args)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cmd, tt.expected) {
+				t.Errorf("ParseArgs() = %+v, want %+v", cmd, tt.expected)
+			}
+		})
+	}
+
+	cmd, err := ParseArgs([]string{"scratch-note", "list", "--tag", "infra", "--tag", "!Done"})
+	if err != nil || !reflect.DeepEqual(cmd.List.Tags, []string{"infra", "!done"}) {
+		t.Errorf("ParseArgs(list --tag) = %v, %v", cmd.List.Tags, err)
+	}
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"scratch-note/notes"
+)
+
+// TemplateData is the data available to note templates
+type TemplateData struct {
+	// Title is the title as given on the command line, before cleaning
+	Title     string
+	Timestamp time.Time
+	Hostname  string
+	// Cwd is the directory scratch-note was run from
+	Cwd string
+	// Project is the name of the git repository containing Cwd, if any
+	Project   string
+	GitBranch string
+	// Vars holds the values passed with --var key=value
+	Vars map[string]string
+}
+
+// varsFlag is a flag.Value collecting repeatable key=value pairs
+type varsFlag map[string]string
+
+func (v *varsFlag) String() string {
+	var pairs []string
+	for key, value := range *v {
+		pairs = append(pairs, key+"="+value)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (v *varsFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("invalid variable %q (want key=value)", value)
+	}
+	if *v == nil {
+		*v = make(map[string]string)
+	}
+	(*v)[strings.TrimSpace(key)] = val
+	return nil
+}
+
+// NewTemplateData collects the template data for a note created at t from
+// the current environment
+func NewTemplateData(title string, t time.Time, vars map[string]string) TemplateData {
+	data := TemplateData{
+		Title:     title,
+		Timestamp: t,
+		Vars:      vars,
+	}
+	if data.Vars == nil {
+		data.Vars = map[string]string{}
+	}
+
+	if hostname, err := os.Hostname(); err == nil {
+		data.Hostname = hostname
+	}
+	if cwd, err := os.Getwd(); err == nil {
+		data.Cwd = cwd
+		data.GitBranch = gitBranch(cwd)
+		if root, _, ok := findGitRepo(cwd); ok {
+			data.Project = filepath.Base(root)
+		}
+	}
+
+	return data
+}
+
+// Frontmatter returns the metadata recorded in a new note's frontmatter
+func (d TemplateData) Frontmatter() notes.Frontmatter {
+	return notes.Frontmatter{
+		Title:   d.Title,
+		Created: d.Timestamp,
+		Cwd:     d.Cwd,
+		Project: d.Project,
+	}
+}
+
+// LoadTemplate reads the template called name from templatesDir. The name may
+// be given with or without the note extension.
+func LoadTemplate(templatesDir, name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid template name: %q", name)
+	}
+
+	candidates := []string{name}
+	if filepath.Ext(name) == "" {
+		candidates = append(candidates, name+".md")
+	}
+
+	for _, candidate := range candidates {
+		content, err := os.ReadFile(filepath.Join(templatesDir, candidate))
+		if err == nil {
+			return string(content), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to read template %s: %v", name, err)
+		}
+	}
+
+	return "", fmt.Errorf("template not found: %s (looked in %s)", name, templatesDir)
+}
+
+// RenderTemplate executes the template text with data. Missing variables
+// render as empty strings.
+func RenderTemplate(name, text string, data TemplateData) ([]byte, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template %s: %v", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render template %s: %v", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// gitBranch returns the branch checked out in the git repository containing
+// dir, the short commit hash for a detached HEAD, or "" outside a repository
+func gitBranch(dir string) string {
+	_, gitDir, ok := findGitRepo(dir)
+	if !ok {
+		return ""
+	}
+	return readGitHead(gitDir)
+}
+
+// findGitRepo walks up from dir to the enclosing git repository and returns
+// its work tree root and git directory
+func findGitRepo(dir string) (root, gitDir string, ok bool) {
+	for {
+		gitPath := filepath.Join(dir, ".git")
+		info, err := os.Stat(gitPath)
+		if err == nil {
+			if info.IsDir() {
+				return dir, gitPath, true
+			}
+
+			// Worktrees and submodules use a file pointing at the git dir
+			content, err := os.ReadFile(gitPath)
+			if err != nil {
+				return "", "", false
+			}
+			target, found := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir: ")
+			if !found {
+				return "", "", false
+			}
+			if !filepath.IsAbs(target) {
+				target = filepath.Join(dir, target)
+			}
+			return dir, target, true
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", "", false
+		}
+		dir = parent
+	}
+}
+
+func readGitHead(gitDir string) string {
+	content, err := os.ReadFile(filepath.Join(gitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+
+	head := strings.TrimSpace(string(content))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if len(head) > 7 {
+		return head[:7]
+	}
+	return head
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"scratch-note/config"
+	"scratch-note/notes"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	data := TemplateData{
+		Title:     "standup",
+		Timestamp: time.Date(2025, 8, 16, 14, 30, 45, 0, time.UTC),
+		Hostname:  "workstation",
+		Cwd:       "/src/project",
+		GitBranch: "main",
+		Vars:      map[string]string{"ticket": "OPS-42"},
+	}
+
+	tests := []struct {
+		name        string
+		text        string
+		expected    string
+		expectError bool
+	}{
+		{
+			name:     "title and timestamp",
+			text:     "# {{.Title}} ({{.Timestamp.Format \"2006-01-02\"}})\n",
+			expected: "# standup (2025-08-16)\n",
+		},
+		{
+			name:     "environment",
+			text:     "{{.Hostname}}:{{.Cwd}}@{{.GitBranch}}",
+			expected: "workstation:/src/project@main",
+		},
+		{
+			name:     "user variable",
+			text:     "Ticket: {{.Vars.ticket}}",
+			expected: "Ticket: OPS-42",
+		},
+		{
+			name:     "missing variable renders empty",
+			text:     "Owner: {{.Vars.owner}}",
+			expected: "Owner: ",
+		},
+		{
+			name:        "parse error",
+			text:        "{{.Title",
+			expectError: true,
+		},
+		{
+			name:        "unknown field",
+			text:        "{{.Nope}}",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := RenderTemplate("test", tt.text, data)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("Expected error, got %q", result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if string(result) != tt.expected {
+				t.Errorf("RenderTemplate() = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLoadTemplate(t *testing.T) {
+	templatesDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(templatesDir, "meeting.md"), []byte("# Meeting"), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write template: %v", err)
+	}
+
+	for _, name := range []string{"meeting", "meeting.md"} {
+		content, err := LoadTemplate(templatesDir, name)
+		if err != nil {
+			t.Errorf("LoadTemplate(%q) unexpected error: %v", name, err)
+			continue
+		}
+		if content != "# Meeting" {
+			t.Errorf("LoadTemplate(%q) = %q, want template content", name, content)
+		}
+	}
+
+	for _, name := range []string{"missing", "", "../meeting"} {
+		if _, err := LoadTemplate(templatesDir, name); err == nil {
+			t.Errorf("LoadTemplate(%q) expected error", name)
+		}
+	}
+}
+
+func TestVarsFlag(t *testing.T) {
+	var vars varsFlag
+	for _, value := range []string{"ticket=OPS-42", "empty=", "url=https://x?a=b"} {
+		if err := vars.Set(value); err != nil {
+			t.Fatalf("Set(%q) unexpected error: %v", value, err)
+		}
+	}
+
+	expected := map[string]string{"ticket": "OPS-42", "empty": "", "url": "https://x?a=b"}
+	for key, value := range expected {
+		if vars[key] != value {
+			t.Errorf("vars[%q] = %q, want %q", key, vars[key], value)
+		}
+	}
+
+	for _, value := range []string{"novalue", "=value"} {
+		if err := vars.Set(value); err == nil {
+			t.Errorf("Set(%q) expected error", value)
+		}
+	}
+}
+
+func TestGitBranch(t *testing.T) {
+	repo := t.TempDir()
+	gitDir := filepath.Join(repo, ".git")
+	if err := os.MkdirAll(gitDir, 0755); err != nil {
+		t.Fatalf("Failed to create git dir: %v", err)
+	}
+	subDir := filepath.Join(repo, "a", "b")
+	if err := os.MkdirAll(subDir, 0755); err != nil {
+		t.Fatalf("Failed to create sub dir: %v", err)
+	}
+
+	writeHead := func(content string) {
+		if err := os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write HEAD: %v", err)
+		}
+	}
+
+	writeHead("ref: refs/heads/feature/templates\n")
+	if branch := gitBranch(subDir); branch != "feature/templates" {
+		t.Errorf("gitBranch() = %q, want %q", branch, "feature/templates")
+	}
+
+	writeHead("0123456789abcdef0123456789abcdef01234567\n")
+	if branch := gitBranch(repo); branch != "0123456" {
+		t.Errorf("gitBranch() on detached HEAD = %q, want %q", branch, "0123456")
+	}
+}
+
+func TestCreateScratchNoteWithContent(t *testing.T) {
+	noteDir := t.TempDir()
+	template := []byte("# Standup\n\n")
+
+	mockEditor := &MockEditor{Content: "- shipped templates\n"}
+	filePath, err := CreateScratchNote("standup", noteDir, time.Now(), mockEditor, NoteOptions{Content: template, DiscardEmpty: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatalf("Failed to read note: %v", err)
+	}
+	if !strings.HasPrefix(string(content), "# Standup\n\n") || !strings.HasSuffix(string(content), "- shipped templates\n") {
+		t.Errorf("Note should start with the template and keep typed content, got %q", content)
+	}
+
+	// A note left identical to its template is discarded
+	_, err = CreateScratchNote("standup", noteDir, time.Now(), &MockEditor{}, NoteOptions{Content: template, DiscardEmpty: true})
+	if !errors.Is(err, ErrNoteDiscarded) {
+		t.Errorf("Expected ErrNoteDiscarded for unchanged template, got %v", err)
+	}
+}
+
+func TestInitialContentFrontmatter(t *testing.T) {
+	created := time.Date(2025, 8, 16, 14, 30, 45, 0, time.UTC)
+
+	content, err := initialContent("Meeting notes", created, &config.Config{Frontmatter: true}, CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	doc, err := notes.Parse(content)
+	if err != nil {
+		t.Fatalf("Failed to parse content: %v", err)
+	}
+	fm, err := doc.Frontmatter()
+	if err != nil {
+		t.Fatalf("Frontmatter() unexpected error: %v", err)
+	}
+	if fm.Title != "Meeting notes" || !fm.Created.Equal(created) || fm.Cwd == "" {
+		t.Errorf("Unexpected frontmatter: %+v", fm)
+	}
+
+	content, err = initialContent("Meeting notes", created, &config.Config{}, CreateOptions{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(content) != 0 {
+		t.Errorf("Content without frontmatter or template should be empty, got %q", content)
+	}
+
+	// Tags need frontmatter even when it is not enabled
+	content, err = initialContent("Meeting notes", created, &config.Config{}, CreateOptions{Tags: []string{"bug", "infra"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(content) != "---\ntags:\n  - bug\n  - infra\n---\n" {
+		t.Errorf("Content with tags = %q", content)
+	}
+}
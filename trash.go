@@ -0,0 +1,409 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"scratch-note/utils"
+)
+
+// trashDirName is the directory inside the notes directory that deleted
+// notes are moved to. It is hidden, so listings and searches skip it.
+const trashDirName = ".trash"
+
+// trashManifestName is the file in the trash recording where each note came from
+const trashManifestName = "manifest.json"
+
+// trashLockName is the file in the trash locked while the manifest is updated.
+// The manifest itself is replaced on every update, so it cannot hold the lock.
+const trashLockName = "manifest.lock"
+
+// Actions of the trash command
+const (
+	TrashActionList    = "list"
+	TrashActionRestore = "restore"
+	TrashActionEmpty   = "empty"
+)
+
+// DeleteOptions holds the options of the delete command
+type DeleteOptions struct {
+	// Refs are paths, list indexes or title fragments of the notes
+	Refs []string
+}
+
+// TrashOptions holds the options of the trash command
+type TrashOptions struct {
+	// Action is one of the TrashAction constants
+	Action string
+	// Refs are trash indexes or fragments of original paths, for restore
+	Refs []string
+	// OlderThan limits empty to notes deleted longer ago than this
+	OlderThan time.Duration
+}
+
+// TrashEntry is a note in the trash
+type TrashEntry struct {
+	// File is the name of the note in the trash directory
+	File string `json:"file"`
+	// Original is the path of the note relative to the notes directory
+	Original string `json:"original"`
+	// Deleted is when the note was moved to the trash
+	Deleted time.Time `json:"deleted"`
+}
+
+// ageFlag is a flag.Value accepting durations such as 30d, 2w or 12h
+type ageFlag struct {
+	d *time.Duration
+}
+
+func (a ageFlag) String() string {
+	if a.d == nil || *a.d == 0 {
+		return ""
+	}
+	return a.d.String()
+}
+
+func (a ageFlag) Set(value string) error {
+	d, err := parseAge(value)
+	if err != nil {
+		return err
+	}
+	*a.d = d
+	return nil
+}
+
+// parseAge parses a duration given in days (30d) or weeks (2w), or in any
+// unit time.ParseDuration accepts
+func parseAge(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if number, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(number)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q (want e.g. 30d, 2w or 12h)", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (want e.g. 30d, 2w or 12h)", value)
+	}
+	return d, nil
+}
+
+// updateTrash runs update on the trash manifest of directory while holding
+// a lock on it, and writes back the entries update returns. Unless create
+// is set, a trash without a manifest is left uncreated and update is run on
+// no entries.
+func updateTrash(directory string, create bool, update func([]TrashEntry) ([]TrashEntry, error)) error {
+	trashDir := filepath.Join(directory, trashDirName)
+	if !create {
+		if _, err := os.Stat(filepath.Join(trashDir, trashManifestName)); os.IsNotExist(err) {
+			_, err := update(nil)
+			return err
+		}
+	}
+	if err := os.MkdirAll(trashDir, 0700); err != nil {
+		return fmt.Errorf("failed to create trash: %v", err)
+	}
+
+	lock, err := os.OpenFile(filepath.Join(trashDir, trashLockName), os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to lock trash manifest: %v", err)
+	}
+	defer lock.Close()
+
+	unlock, err := utils.LockFile(lock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := readTrashManifest(trashDir)
+	if err != nil {
+		return err
+	}
+
+	// Entries are written back even when update fails part way, so the
+	// manifest matches the notes that were already moved
+	entries, updateErr := update(entries)
+	if err := writeTrashManifest(trashDir, entries); err != nil {
+		return err
+	}
+	return updateErr
+}
+
+// readTrashManifest returns the entries of the manifest in trashDir, none
+// when there is no manifest
+func readTrashManifest(trashDir string) ([]TrashEntry, error) {
+	data, err := os.ReadFile(filepath.Join(trashDir, trashManifestName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trash manifest: %v", err)
+	}
+
+	var entries []TrashEntry
+	if len(strings.TrimSpace(string(data))) > 0 {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("invalid trash manifest: %v", err)
+		}
+	}
+	return entries, nil
+}
+
+// writeTrashManifest replaces the manifest in trashDir with entries. It is
+// written to a temporary file first, so a crash never leaves it partial.
+func writeTrashManifest(trashDir string, entries []TrashEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode trash manifest: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(trashDir, trashManifestName+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to write trash manifest: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write trash manifest: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), filepath.Join(trashDir, trashManifestName)); err != nil {
+		return fmt.Errorf("failed to write trash manifest: %v", err)
+	}
+	return nil
+}
+
+// readTrash returns the notes in the trash of directory, newest first. It
+// only reads the manifest, which is replaced as a whole on every update.
+func readTrash(directory string) ([]TrashEntry, error) {
+	entries, err := readTrashManifest(filepath.Join(directory, trashDirName))
+	if err != nil {
+		return nil, err
+	}
+	sortTrash(entries)
+	return entries, nil
+}
+
+// sortTrash orders entries newest first, the order of trash list
+func sortTrash(entries []TrashEntry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Deleted.After(entries[j].Deleted)
+	})
+}
+
+// DeleteNotes moves the notes refs refer to into the trash of directory,
+// recording them in the manifest, and reports each to w. Every ref must
+// match exactly one note inside directory.
+func DeleteNotes(directory string, refs []string, now time.Time, w io.Writer) error {
+	root, err := filepath.Abs(directory)
+	if err != nil {
+		return err
+	}
+
+	// Resolve everything first so list indexes do not shift while deleting
+	var paths []string
+	seen := map[string]bool{}
+	for _, ref := range refs {
+		path, err := ResolveUniqueNote(directory, ref)
+		if err != nil {
+			return err
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("%s is not in the scratch-note directory", path)
+		}
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	return updateTrash(directory, true, func(entries []TrashEntry) ([]TrashEntry, error) {
+		for _, path := range paths {
+			rel, _ := filepath.Rel(root, path)
+			file, err := freeTrashName(root, filepath.Base(path))
+			if err != nil {
+				return entries, err
+			}
+			if err := os.Rename(path, filepath.Join(root, trashDirName, file)); err != nil {
+				return entries, fmt.Errorf("failed to move %s to the trash: %v", rel, err)
+			}
+			removeEmptyDirs(root, filepath.Dir(path))
+
+			entries = append(entries, TrashEntry{File: file, Original: filepath.ToSlash(rel), Deleted: now})
+			fmt.Fprintf(w, "Moved to trash: %s\n", rel)
+		}
+		return entries, nil
+	})
+}
+
+// freeTrashName returns name, or name with a number added before its
+// extension, whichever is not yet taken in the trash of directory
+func freeTrashName(directory, name string) (string, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 1; n < 1000; n++ {
+		candidate := name
+		if n > 1 {
+			candidate = fmt.Sprintf("%s.%d%s", base, n, ext)
+		}
+		if candidate == trashManifestName || candidate == trashLockName {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(directory, trashDirName, candidate)); os.IsNotExist(err) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("failed to move %s to the trash: too many deleted notes named like it", name)
+}
+
+// ListTrash writes the notes in the trash of directory to w, newest first,
+// numbered for trash restore
+func ListTrash(directory string, w io.Writer) error {
+	entries, err := readTrash(directory)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "Trash is empty")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tDELETED\tORIGINAL")
+	for i, entry := range entries {
+		fmt.Fprintf(tw, "%d\t%s\t%s\n", i+1, entry.Deleted.Local().Format("2006-01-02 15:04:05"), entry.Original)
+	}
+	return tw.Flush()
+}
+
+// findTrashEntry returns the position in entries, sorted newest first, of
+// the entry ref refers to: a 1-based index as shown by trash list, or a
+// case-insensitive fragment of the original path matching a single entry
+func findTrashEntry(entries []TrashEntry, ref string) (int, error) {
+	if index, err := strconv.Atoi(ref); err == nil {
+		if index < 1 || index > len(entries) {
+			return 0, fmt.Errorf("no note at trash index %d (%d notes)", index, len(entries))
+		}
+		return index - 1, nil
+	}
+
+	fragment := strings.ToLower(ref)
+	var matches []int
+	for i, entry := range entries {
+		if strings.Contains(strings.ToLower(entry.Original), fragment) {
+			matches = append(matches, i)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return 0, fmt.Errorf("no note in the trash matches %q", ref)
+	case 1:
+		return matches[0], nil
+	}
+	var names []string
+	for _, i := range matches {
+		names = append(names, "  "+entries[i].Original)
+	}
+	return 0, fmt.Errorf("%q matches %d notes in the trash:\n%s", ref, len(matches), strings.Join(names, "\n"))
+}
+
+// RestoreNotes moves the notes refs refer to out of the trash of directory
+// back to their original paths and reports each to w. A note whose original
+// path has been taken since is left in the trash.
+func RestoreNotes(directory string, refs []string, w io.Writer) error {
+	return updateTrash(directory, false, func(entries []TrashEntry) ([]TrashEntry, error) {
+		sortTrash(entries)
+
+		// Resolve everything first so trash indexes do not shift while restoring
+		restore := map[int]bool{}
+		for _, ref := range refs {
+			i, err := findTrashEntry(entries, ref)
+			if err != nil {
+				return entries, err
+			}
+			restore[i] = true
+		}
+
+		var kept []TrashEntry
+		var failed error
+		for i, entry := range entries {
+			if !restore[i] || failed != nil {
+				kept = append(kept, entry)
+				continue
+			}
+			if err := restoreEntry(directory, entry); err != nil {
+				failed = err
+				kept = append(kept, entry)
+				continue
+			}
+			fmt.Fprintf(w, "Restored: %s\n", entry.Original)
+		}
+		return kept, failed
+	})
+}
+
+// restoreEntry moves the note of entry back to its original path
+func restoreEntry(directory string, entry TrashEntry) error {
+	target := filepath.Join(directory, filepath.FromSlash(entry.Original))
+	if _, err := os.Lstat(target); err == nil {
+		return fmt.Errorf("cannot restore %s: a note already exists there", entry.Original)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %v", err)
+	}
+	if err := os.Rename(filepath.Join(directory, trashDirName, entry.File), target); err != nil {
+		return fmt.Errorf("failed to restore %s: %v", entry.Original, err)
+	}
+	return nil
+}
+
+// EmptyTrash permanently removes the notes in the trash of directory that
+// were deleted more than olderThan before now, or all of them when olderThan
+// is zero, and reports how many to w
+func EmptyTrash(directory string, olderThan time.Duration, now time.Time, w io.Writer) error {
+	removed := 0
+	err := updateTrash(directory, false, func(entries []TrashEntry) ([]TrashEntry, error) {
+		var kept []TrashEntry
+		for i, entry := range entries {
+			if olderThan > 0 && now.Sub(entry.Deleted) <= olderThan {
+				kept = append(kept, entry)
+				continue
+			}
+			err := os.Remove(filepath.Join(directory, trashDirName, entry.File))
+			if err != nil && !os.IsNotExist(err) {
+				return append(kept, entries[i:]...), fmt.Errorf("failed to remove %s: %v", entry.Original, err)
+			}
+			removed++
+		}
+		return kept, nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Permanently deleted %d notes from the trash\n", removed)
+	return nil
+}
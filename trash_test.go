@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeleteAndRestoreNotes(t *testing.T) {
+	useLayout(t, "monthly")
+	dir := t.TempDir()
+	first := writeTestNoteContent(t, dir, "2025/08/2025-08-15_100000_disk-full.md", "first\n")
+	second := writeTestNoteContent(t, dir, "2025/09/2025-09-01_100000_disk-full.md", "second\n")
+	writeTestNoteContent(t, dir, "2025/09/2025-09-02_100000_standup.md", "kept\n")
+	deleted := time.Date(2025, 9, 10, 12, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	if err := DeleteNotes(dir, []string{"disk"}, deleted, &out); err == nil {
+		t.Error("DeleteNotes() with an ambiguous note should fail")
+	}
+	if err := DeleteNotes(dir, []string{"2025-08-15", "2025-09-01"}, deleted, &out); err != nil {
+		t.Fatalf("DeleteNotes() unexpected error: %v", err)
+	}
+	for _, path := range []string{first, second} {
+		if _, err := os.Stat(path); !os.IsNotExist(err) {
+			t.Errorf("%s should have been moved to the trash", path)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2025", "08")); !os.IsNotExist(err) {
+		t.Error("Directory left empty should be removed")
+	}
+
+	// Deleted notes are no longer listed
+	notes, err := collectNotes(dir)
+	if err != nil || len(notes) != 1 {
+		t.Errorf("collectNotes() = %d notes, %v; want only the kept note", len(notes), err)
+	}
+
+	// Both deleted notes have the same filename in the trash
+	entries, err := readTrash(dir)
+	if err != nil {
+		t.Fatalf("readTrash() unexpected error: %v", err)
+	}
+	expected := []TrashEntry{
+		{File: "2025-08-15_100000_disk-full.md", Original: "2025/08/2025-08-15_100000_disk-full.md", Deleted: deleted},
+		{File: "2025-09-01_100000_disk-full.md", Original: "2025/09/2025-09-01_100000_disk-full.md", Deleted: deleted},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("readTrash() = %+v, want %+v", entries, expected)
+	}
+
+	out.Reset()
+	if err := ListTrash(dir, &out); err != nil {
+		t.Fatalf("ListTrash() unexpected error: %v", err)
+	}
+	if !strings.Contains(out.String(), "2025/08/2025-08-15_100000_disk-full.md") {
+		t.Errorf("ListTrash() =\n%s", out.String())
+	}
+
+	if err := RestoreNotes(dir, []string{"2025/08"}, &out); err != nil {
+		t.Fatalf("RestoreNotes() unexpected error: %v", err)
+	}
+	if content, err := os.ReadFile(first); err != nil || string(content) != "first\n" {
+		t.Errorf("Restored note = %q, %v", content, err)
+	}
+	if entries, _ := readTrash(dir); len(entries) != 1 {
+		t.Errorf("Trash should have 1 note left, has %d", len(entries))
+	}
+
+	// A note is not restored over one created in its place
+	writeTestNoteContent(t, dir, "2025/09/2025-09-01_100000_disk-full.md", "new\n")
+	if err := RestoreNotes(dir, []string{"1"}, &out); err == nil {
+		t.Error("RestoreNotes() over an existing note should fail")
+	}
+	if entries, _ := readTrash(dir); len(entries) != 1 {
+		t.Errorf("Note that could not be restored should stay in the trash, %d left", len(entries))
+	}
+}
+
+func TestDeleteNotesSameName(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestNoteContent(t, dir, "2025-08-15_100000_idea.md", "one\n")
+	deleted := time.Date(2025, 8, 16, 0, 0, 0, 0, time.UTC)
+	if err := DeleteNotes(dir, []string{"idea"}, deleted, &bytes.Buffer{}); err != nil {
+		t.Fatalf("DeleteNotes() unexpected error: %v", err)
+	}
+	writeTestNoteContent(t, dir, "2025-08-15_100000_idea.md", "two\n")
+	if err := DeleteNotes(dir, []string{path}, deleted.Add(time.Hour), &bytes.Buffer{}); err != nil {
+		t.Fatalf("DeleteNotes() unexpected error: %v", err)
+	}
+
+	entries, _ := readTrash(dir)
+	if len(entries) != 2 || entries[0].File != "2025-08-15_100000_idea.2.md" {
+		t.Errorf("readTrash() = %+v, want the newer note renamed", entries)
+	}
+
+	outside := writeTestNoteContent(t, t.TempDir(), "2025-08-15_100000_idea.md", "")
+	if err := DeleteNotes(dir, []string{outside}, deleted, &bytes.Buffer{}); err == nil {
+		t.Error("DeleteNotes() outside the notes directory should fail")
+	}
+}
+
+func TestTrashManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	// Listing an empty trash creates nothing
+	var out bytes.Buffer
+	if err := ListTrash(dir, &out); err != nil || out.String() != "Trash is empty\n" {
+		t.Errorf("ListTrash() = %q, %v", out.String(), err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, trashDirName)); !os.IsNotExist(err) {
+		t.Error("ListTrash() should not create the trash directory")
+	}
+	if err := EmptyTrash(dir, 0, time.Now(), &out); err != nil {
+		t.Errorf("EmptyTrash() unexpected error: %v", err)
+	}
+	if err := RestoreNotes(dir, []string{"1"}, &out); err == nil {
+		t.Error("RestoreNotes() expected error for an empty trash")
+	}
+	if _, err := os.Stat(filepath.Join(dir, trashDirName)); !os.IsNotExist(err) {
+		t.Error("Emptying and restoring from an empty trash should not create the trash directory")
+	}
+
+	writeTestNoteContent(t, dir, "2025-08-15_100000_idea.md", "")
+	if err := DeleteNotes(dir, []string{"idea"}, time.Now(), &bytes.Buffer{}); err != nil {
+		t.Fatalf("DeleteNotes() unexpected error: %v", err)
+	}
+	manifest := filepath.Join(dir, trashDirName, trashManifestName)
+	info, err := os.Stat(manifest)
+	if err != nil {
+		t.Fatalf("Manifest should exist: %v", err)
+	}
+
+	// The manifest is replaced through a temporary file that is gone afterwards
+	expected := []string{".trash/2025-08-15_100000_idea.md", ".trash/" + trashManifestName, ".trash/" + trashLockName}
+	if files := listRelative(t, dir); !reflect.DeepEqual(files, expected) {
+		t.Errorf("Files = %v, want %v", files, expected)
+	}
+
+	// Reading the trash leaves the manifest alone
+	if _, err := readTrash(dir); err != nil {
+		t.Fatalf("readTrash() unexpected error: %v", err)
+	}
+	if after, err := os.Stat(manifest); err != nil || !os.SameFile(info, after) || !after.ModTime().Equal(info.ModTime()) {
+		t.Error("readTrash() should not rewrite the manifest")
+	}
+}
+
+func TestEmptyTrash(t *testing.T) {
+	dir := t.TempDir()
+	writeTestNoteContent(t, dir, "2025-07-01_100000_old.md", "")
+	writeTestNoteContent(t, dir, "2025-08-15_100000_recent.md", "")
+	now := time.Date(2025, 8, 20, 0, 0, 0, 0, time.UTC)
+	if err := DeleteNotes(dir, []string{"old"}, now.AddDate(0, 0, -40), &bytes.Buffer{}); err != nil {
+		t.Fatalf("DeleteNotes() unexpected error: %v", err)
+	}
+	if err := DeleteNotes(dir, []string{"recent"}, now.AddDate(0, 0, -2), &bytes.Buffer{}); err != nil {
+		t.Fatalf("DeleteNotes() unexpected error: %v", err)
+	}
+
+	var out bytes.Buffer
+	if err := EmptyTrash(dir, 30*24*time.Hour, now, &out); err != nil {
+		t.Fatalf("EmptyTrash() unexpected error: %v", err)
+	}
+	entries, _ := readTrash(dir)
+	if len(entries) != 1 || entries[0].File != "2025-08-15_100000_recent.md" {
+		t.Errorf("readTrash() = %+v, want the recent note only", entries)
+	}
+	if _, err := os.Stat(filepath.Join(dir, trashDirName, "2025-07-01_100000_old.md")); !os.IsNotExist(err) {
+		t.Error("Old note should be removed from the trash")
+	}
+
+	if err := EmptyTrash(dir, 0, now, &out); err != nil {
+		t.Fatalf("EmptyTrash() unexpected error: %v", err)
+	}
+	if entries, _ := readTrash(dir); len(entries) != 0 {
+		t.Errorf("Trash should be empty, has %+v", entries)
+	}
+
+	// A note that cannot be removed stops emptying without a count
+	writeTestNoteContent(t, dir, "2025-08-16_100000_stuck.md", "")
+	if err := DeleteNotes(dir, []string{"stuck"}, now, &bytes.Buffer{}); err != nil {
+		t.Fatalf("DeleteNotes() unexpected error: %v", err)
+	}
+	stuck := filepath.Join(dir, trashDirName, "2025-08-16_100000_stuck.md")
+	if err := os.Remove(stuck); err != nil {
+		t.Fatalf("Failed to remove trashed note: %v", err)
+	}
+	writeTestNoteContent(t, stuck, "keep.md", "")
+	out.Reset()
+	if err := EmptyTrash(dir, 0, now, &out); err == nil {
+		t.Error("EmptyTrash() expected error for a note it cannot remove")
+	}
+	if out.Len() != 0 {
+		t.Errorf("EmptyTrash() output after a failure = %q, want none", out.String())
+	}
+	if entries, _ := readTrash(dir); len(entries) != 1 {
+		t.Errorf("readTrash() = %+v, want the stuck note kept", entries)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+	for value, expected := range tests {
+		if got, err := parseAge(value); err != nil || got != expected {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", value, got, err, expected)
+		}
+	}
+	for _, value := range []string{"", "d", "-1d", "soon"} {
+		if _, err := parseAge(value); err == nil {
+			t.Errorf("parseAge(%q) expected error", value)
+		}
+	}
+}
+
+func TestParseArgsTrash(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		expected    Command
+		expectError bool
+	}{
+		{
+			name:     "delete",
+			args:     []string{"scratch-note", "rm", "1", "standup"},
+			expected: Command{Type: CommandTypeDelete, Delete: DeleteOptions{Refs: []string{"1", "standup"}}},
+		},
+		{
+			name:     "trash lists by default",
+			args:     []string{"scratch-note", "trash"},
+			expected: Command{Type: CommandTypeTrash, Trash: TrashOptions{Action: TrashActionList}},
+		},
+		{
+			name:     "trash restore",
+			args:     []string{"scratch-note", "trash", "restore", "2"},
+			expected: Command{Type: CommandTypeTrash, Trash: TrashOptions{Action: TrashActionRestore, Refs: []string{"2"}}},
+		},
+		{
+			name:     "trash empty older than",
+			args:     []string{"scratch-note", "trash", "empty", "--older-than", "30d"},
+			expected: Command{Type: CommandTypeTrash, Trash: TrashOptions{Action: TrashActionEmpty, OlderThan: 30 * 24 * time.Hour}},
+		},
+		{name: "delete without note", args: []string{"scratch-note", "delete"}, expectError: true},
+		{name: "restore without note", args: []string{"scratch-note", "trash", "restore"}, expectError: true},
+		{name: "older than with list", args: []string{"scratch-note", "trash", "list", "--older-than", "1d"}, expectError: true},
+		{name: "unknown trash action", args: []string{"scratch-note", "trash", "purge"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := ParseArgs(tt.args)
+			if tt.expectError {
+				if err == nil {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(cmd, tt.expected) {
+				t.Errorf("ParseArgs() = %+v, want %+v", cmd, tt.expected)
+			}
+		})
+	}
+}
@@ -2,40 +2,104 @@ package utils
 
 import (
 	"fmt"
+	"path"
 	"regexp"
 	"strings"
 	"time"
 )
 
-// GenerateFileName generates a timestamped filename with optional title
+// TimestampLayout is the time layout used at the start of every note filename
+const TimestampLayout = "2006-01-02_150405"
+
+// DailyLayout is the time layout of daily note filenames, see GenerateDailyFileName
+const DailyLayout = "2006-01-02"
+
+// NoteExtension is the file extension of notes created by GenerateFileName
+const NoteExtension = ".md"
+
+// NoteName is the parsed form of a note filename:
+// YYYY-MM-DD_HHMMSS[-SEQ][_slug].ext
+type NoteName struct {
+	// Time is the creation timestamp, in the local time zone, to the second
+	Time time.Time
+	// Slug is the cleaned title, empty for untitled notes
+	Slug string
+	// Ext is the file extension including the leading dot
+	Ext string
+	// Seq distinguishes notes created in the same second with the same title;
+	// zero means no sequence suffix
+	Seq int
+}
+
+// String formats n back into a filename of the default format; it is the
+// inverse of ParseFileName
+func (n NoteName) String() string {
+	return defaultFormat.Format(n)
+}
+
+// Slug returns title cleaned the way GenerateFileName puts it in a filename
+func Slug(title string) string {
+	return defaultFormat.Slug(title)
+}
+
+// NewNoteName returns the name of a new note with the given title and creation time
+func NewNoteName(title string, t time.Time) NoteName {
+	return defaultFormat.NewName(title, t)
+}
+
+// GenerateFileName generates a timestamped filename with optional title in
+// the default format: YYYY-MM-DD_HHMMSS[_title].md
 func GenerateFileName(title string, t time.Time) string {
-	// Format: YYYY-MM-DD_HHMMSS
-	timestamp := t.Format("2006-01-02_150405")
-	
-	if title == "" {
-		return timestamp + ".md"
+	return defaultFormat.Format(NewNoteName(title, t))
+}
+
+// ParseFileName parses a filename produced by GenerateFileName (or
+// NoteName.String) back into its timestamp, slug, extension and sequence.
+// Any extension is accepted, not only NoteExtension.
+func ParseFileName(name string) (NoteName, error) {
+	ext := path.Ext(name)
+	if ext == "" || ext == NoteExtension {
+		return defaultFormat.Parse(name)
+	}
+	format, err := NewFileNameFormat(DefaultFileNameTemplate, ext, DefaultSlugSeparator)
+	if err != nil {
+		return NoteName{}, fmt.Errorf("not a scratch-note filename: %q", name)
 	}
-	
-	// Clean title: replace spaces and special characters with hyphens
-	cleanTitle := cleanTitle(title)
-	return fmt.Sprintf("%s_%s.md", timestamp, cleanTitle)
-}
-
-// cleanTitle removes special characters and replaces spaces with hyphens
-func cleanTitle(title string) string {
-	// Replace spaces with hyphens
-	cleaned := strings.ReplaceAll(title, " ", "-")
-	
-	// Remove or replace special characters with hyphens
-	reg := regexp.MustCompile(`[/\\:*?"<>|]`)
-	cleaned = reg.ReplaceAllString(cleaned, "-")
-	
-	// Remove multiple consecutive hyphens
-	reg = regexp.MustCompile(`-+`)
-	cleaned = reg.ReplaceAllString(cleaned, "-")
-	
-	// Trim hyphens from start and end
-	cleaned = strings.Trim(cleaned, "-")
-	
+	return format.Parse(name)
+}
+
+// GenerateDailyFileName returns the filename of the daily note for the day of t:
+// YYYY-MM-DD.md
+func GenerateDailyFileName(t time.Time) string {
+	return defaultFormat.DailyFileName(t)
+}
+
+// ParseDailyFileName parses a filename produced by GenerateDailyFileName and
+// returns the start of its day in the local time zone
+func ParseDailyFileName(name string) (time.Time, error) {
+	return defaultFormat.ParseDailyFileName(name)
+}
+
+// specialChars are the characters replaced in titles besides spaces
+const specialChars = `/\:*?"<>|`
+
+// slugify replaces spaces and special characters in title with separator,
+// collapsing repeated separators and trimming them from both ends
+func slugify(title, separator string) string {
+	reg := regexp.MustCompile(`[ ` + regexp.QuoteMeta(specialChars) + `]`)
+	cleaned := reg.ReplaceAllString(title, separator)
+
+	// Remove multiple consecutive separators
+	reg = regexp.MustCompile(`(?:` + regexp.QuoteMeta(separator) + `)+`)
+	cleaned = reg.ReplaceAllString(cleaned, separator)
+
+	// Trim separators from start and end
+	for strings.HasPrefix(cleaned, separator) {
+		cleaned = cleaned[len(separator):]
+	}
+	for strings.HasSuffix(cleaned, separator) {
+		cleaned = cleaned[:len(cleaned)-len(separator)]
+	}
+
 	return cleaned
-}
\ No newline at end of file
+}
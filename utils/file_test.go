@@ -78,4 +78,176 @@ func TestGenerateFileNameCurrentTime(t *testing.T) {
 	if timeStr != expectedStr {
 		t.Errorf("Time in filename %s does not match expected %s", timeStr, expectedStr)
 	}
-}
\ No newline at end of file
+}
+func TestGenerateFileNameTitleCleanedToEmpty(t *testing.T) {
+	testTime := time.Date(2025, 8, 16, 14, 30, 45, 0, time.UTC)
+	result := GenerateFileName("///", testTime)
+	expected := "2025-08-16_143045.md"
+
+	if result != expected {
+		t.Errorf("GenerateFileName with title of only special characters = %q, want %q", result, expected)
+	}
+}
+
+func TestParseFileName(t *testing.T) {
+	tests := []struct {
+		name        string
+		filename    string
+		expected    NoteName
+		expectError bool
+	}{
+		{
+			name:     "without title",
+			filename: "2025-08-16_143045.md",
+			expected: NoteName{Time: time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local), Ext: ".md"},
+		},
+		{
+			name:     "with title",
+			filename: "2025-08-16_143045_meeting-notes.md",
+			expected: NoteName{Time: time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local), Slug: "meeting-notes", Ext: ".md"},
+		},
+		{
+			name:     "title containing dots and underscores",
+			filename: "2025-08-16_143045_v1.2_release_2.md",
+			expected: NoteName{Time: time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local), Slug: "v1.2_release_2", Ext: ".md"},
+		},
+		{
+			name:     "with sequence",
+			filename: "2025-08-16_143045-2_meeting-notes.md",
+			expected: NoteName{Time: time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local), Slug: "meeting-notes", Ext: ".md", Seq: 2},
+		},
+		{
+			name:     "with sequence without title",
+			filename: "2025-08-16_143045-12.md",
+			expected: NoteName{Time: time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local), Ext: ".md", Seq: 12},
+		},
+		{
+			name:     "other extension",
+			filename: "2025-08-16_143045_todo.txt",
+			expected: NoteName{Time: time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local), Slug: "todo", Ext: ".txt"},
+		},
+		{
+			name:        "no extension",
+			filename:    "2025-08-16_143045",
+			expectError: true,
+		},
+		{
+			name:        "not a timestamp",
+			filename:    "README.md",
+			expectError: true,
+		},
+		{
+			name:        "invalid date",
+			filename:    "2025-13-16_143045.md",
+			expectError: true,
+		},
+		{
+			name:        "missing title separator",
+			filename:    "2025-08-16_143045meeting.md",
+			expectError: true,
+		},
+		{
+			name:        "empty title after separator",
+			filename:    "2025-08-16_143045_.md",
+			expectError: true,
+		},
+		{
+			name:        "non-numeric sequence",
+			filename:    "2025-08-16_143045-x_meeting.md",
+			expectError: true,
+		},
+		{
+			name:        "zero-padded sequence",
+			filename:    "2025-08-16_143045-02.md",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseFileName(tt.filename)
+
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("ParseFileName(%q) expected error, got %+v", tt.filename, result)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("ParseFileName(%q) unexpected error: %v", tt.filename, err)
+			}
+
+			if !result.Time.Equal(tt.expected.Time) || result.Slug != tt.expected.Slug || result.Ext != tt.expected.Ext || result.Seq != tt.expected.Seq {
+				t.Errorf("ParseFileName(%q) = %+v, want %+v", tt.filename, result, tt.expected)
+			}
+
+			if result.String() != tt.filename {
+				t.Errorf("ParseFileName(%q).String() = %q, want original filename", tt.filename, result.String())
+			}
+		})
+	}
+}
+
+func TestDailyFileName(t *testing.T) {
+	day := time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local)
+
+	name := GenerateDailyFileName(day)
+	if name != "2025-08-16.md" {
+		t.Errorf("GenerateDailyFileName() = %q, want %q", name, "2025-08-16.md")
+	}
+
+	parsed, err := ParseDailyFileName(name)
+	if err != nil {
+		t.Fatalf("ParseDailyFileName(%q) unexpected error: %v", name, err)
+	}
+	if !parsed.Equal(time.Date(2025, 8, 16, 0, 0, 0, 0, time.Local)) {
+		t.Errorf("ParseDailyFileName(%q) = %v, want start of day", name, parsed)
+	}
+
+	for _, invalid := range []string{"2025-08-16_143045.md", "2025-08-16.txt", "2025-13-01.md", "notes.md"} {
+		if _, err := ParseDailyFileName(invalid); err == nil {
+			t.Errorf("ParseDailyFileName(%q) expected error", invalid)
+		}
+	}
+}
+
+func FuzzGenerateFileNameRoundTrip(f *testing.F) {
+	f.Add("", int64(1755354645))
+	f.Add("meeting notes", int64(1755354645))
+	f.Add("notes/with\\special:chars", int64(0))
+	f.Add("v1.2 release_2", int64(253402300799))
+	f.Add("-2_tricky", int64(1755354645))
+	f.Add("...", int64(-62135596800))
+
+	f.Fuzz(func(t *testing.T, title string, sec int64) {
+		// Restrict to years 0001-9999, which format as four digits
+		const minSec, maxSec = -62135596800, 253402300799
+		if sec < minSec || sec > maxSec {
+			sec = minSec + (sec%(maxSec-minSec)+(maxSec-minSec))%(maxSec-minSec)
+		}
+		testTime := time.Unix(sec, 0).In(time.Local)
+
+		filename := GenerateFileName(title, testTime)
+		parsed, err := ParseFileName(filename)
+		if err != nil {
+			t.Fatalf("ParseFileName(GenerateFileName(%q)) = %q: %v", title, filename, err)
+		}
+
+		if got, want := parsed.Time.Format(TimestampLayout), testTime.Format(TimestampLayout); got != want {
+			t.Errorf("timestamp = %s, want %s", got, want)
+		}
+
+		if parsed.Slug != Slug(title) {
+			t.Errorf("slug = %q, want %q", parsed.Slug, Slug(title))
+		}
+
+		if parsed.Ext != NoteExtension || parsed.Seq != 0 {
+			t.Errorf("ext/seq = %q/%d, want %q/0", parsed.Ext, parsed.Seq, NoteExtension)
+		}
+
+		if parsed.String() != filename {
+			t.Errorf("String() = %q, want %q", parsed.String(), filename)
+		}
+	})
+}
@@ -0,0 +1,490 @@
+package utils
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults of FileNameFormat; together they produce the names documented on NoteName
+const (
+	// DefaultFileNameTemplate puts the sequence number of a taken name after
+	// the timestamp as -2, -3, ..., where it cannot be mistaken for the end
+	// of a title such as release_2
+	DefaultFileNameTemplate = "{date:2006-01-02}_{time:150405}-{seq}_{slug}"
+	DefaultSlugSeparator    = "-"
+)
+
+// Layouts of the notes directory, see FileNameFormat.WithLayout
+const (
+	LayoutFlat    = "flat"
+	LayoutYearly  = "yearly"
+	LayoutMonthly = "monthly"
+	LayoutDaily   = "daily"
+)
+
+// layoutDirs maps each layout to the time layout of the directories it puts
+// notes in, in the order layouts are tried when parsing
+var layoutDirs = []struct{ layout, dirs string }{
+	{LayoutFlat, ""},
+	{LayoutYearly, "2006/"},
+	{LayoutMonthly, "2006/01/"},
+	{LayoutDaily, "2006/01/02/"},
+}
+
+// partKind identifies the kind of a filename template part
+type partKind int
+
+const (
+	partLiteral partKind = iota
+	partTime
+	partSeq
+	partSlug
+)
+
+// namePart is a literal or placeholder of a compiled filename template
+type namePart struct {
+	kind partKind
+	// text is the literal text, or the time layout of a time placeholder
+	text string
+	// prefix is written before a sequence number or slug, and only with it
+	prefix string
+}
+
+// FileNameFormat describes how note filenames are laid out. Create one with
+// NewFileNameFormat.
+type FileNameFormat struct {
+	// Template lays out the name without the extension, see NewFileNameFormat
+	Template string
+	// Ext is the file extension including the leading dot
+	Ext string
+	// Separator replaces spaces and special characters in title slugs
+	Separator string
+	// Layout names the date directories notes are put in, e.g. LayoutMonthly
+	Layout string
+
+	parts   []namePart
+	pattern *regexp.Regexp
+}
+
+// defaultFormat is the format GenerateFileName, ParseFileName and the other
+// helpers of file.go wrap
+var defaultFormat = mustFileNameFormat(DefaultFileNameTemplate, NoteExtension, DefaultSlugSeparator)
+
+// DefaultFileNameFormat returns the built-in format: YYYY-MM-DD_HHMMSS[-SEQ][_slug].md
+func DefaultFileNameFormat() *FileNameFormat {
+	return defaultFormat
+}
+
+func mustFileNameFormat(template, ext, separator string) *FileNameFormat {
+	f, err := NewFileNameFormat(template, ext, separator)
+	if err != nil {
+		panic(err)
+	}
+	return f
+}
+
+// NewFileNameFormat compiles a filename format. Empty arguments take the
+// defaults. The template may contain these placeholders:
+//   - {date:layout} and {time:layout}: the creation time in a Go time layout;
+//     {date} and {time} alone mean 2006-01-02 and 150405
+//   - {seq}: the sequence number of notes created with the same name
+//   - {slug}: the cleaned title
+//
+// The literal text directly before {seq} or {slug} is only written along with
+// them, so untitled notes do not end in a separator. Without {seq}, "-{seq}"
+// follows the last time placeholder. A "/" in the template puts notes in
+// subdirectories.
+func NewFileNameFormat(template, ext, separator string) (*FileNameFormat, error) {
+	if template == "" {
+		template = DefaultFileNameTemplate
+	}
+	if ext == "" {
+		ext = NoteExtension
+	}
+	if !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	if separator == "" {
+		separator = DefaultSlugSeparator
+	}
+
+	if len(ext) == 1 || strings.ContainsAny(ext[1:], `./\`) {
+		return nil, fmt.Errorf("invalid file extension %q", ext)
+	}
+	if strings.ContainsAny(separator, specialChars) {
+		return nil, fmt.Errorf("invalid separator %q: must not contain any of %s", separator, specialChars)
+	}
+
+	parts, err := parseTemplate(template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filename template %q: %v", template, err)
+	}
+
+	f := &FileNameFormat{Template: template, Ext: ext, Separator: separator, Layout: LayoutFlat, parts: parts}
+	f.pattern = regexp.MustCompile(f.regexp())
+
+	if err := f.check(); err != nil {
+		return nil, fmt.Errorf("invalid filename template %q: %v", template, err)
+	}
+	return f, nil
+}
+
+// WithLayout returns a copy of f putting notes in the date directories of
+// layout: none for flat, 2025/ for yearly, 2025/08/ for monthly and
+// 2025/08/16/ for daily. An empty layout means flat.
+func (f *FileNameFormat) WithLayout(layout string) (*FileNameFormat, error) {
+	if layout == "" {
+		layout = LayoutFlat
+	}
+	if layoutDir(layout) == "" && layout != LayoutFlat {
+		return nil, fmt.Errorf("invalid layout %q (want flat, yearly, monthly or daily)", layout)
+	}
+	laidOut := *f
+	laidOut.Layout = layout
+	return &laidOut, nil
+}
+
+// layoutDir returns the time layout of the directories of layout
+func layoutDir(layout string) string {
+	for _, l := range layoutDirs {
+		if l.layout == layout {
+			return l.dirs
+		}
+	}
+	return ""
+}
+
+// parseInLayouts strips the date directories of each layout from name, the
+// layout of f first, and returns the time parse finds in the rest of the
+// first one where that time matches the directories
+func (f *FileNameFormat) parseInLayouts(name string, parse func(rest string) (time.Time, error)) error {
+	layouts := []string{f.Layout}
+	for _, l := range layoutDirs {
+		if l.layout != f.Layout {
+			layouts = append(layouts, l.layout)
+		}
+	}
+
+	segments := strings.Split(name, "/")
+	for _, layout := range layouts {
+		dirs := layoutDir(layout)
+		depth := strings.Count(dirs, "/")
+		if len(segments) <= depth {
+			continue
+		}
+		prefix := strings.Join(segments[:depth], "/")
+		if depth > 0 {
+			prefix += "/"
+		}
+
+		t, err := parse(strings.Join(segments[depth:], "/"))
+		if err == nil && t.Format(dirs) == prefix {
+			return nil
+		}
+	}
+	return fmt.Errorf("not a scratch-note filename: %q", name)
+}
+
+// parseTemplate splits template into its parts and gives {seq} and {slug}
+// the literal text before them as prefix
+func parseTemplate(template string) ([]namePart, error) {
+	var parts []namePart
+	seqs, slugs, times := 0, 0, 0
+	lastTime := -1
+
+	rest := template
+	for rest != "" {
+		start := strings.IndexByte(rest, '{')
+		if start == -1 {
+			parts = append(parts, namePart{kind: partLiteral, text: rest})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, namePart{kind: partLiteral, text: rest[:start]})
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end == -1 {
+			return nil, fmt.Errorf("unclosed placeholder")
+		}
+		name, arg, _ := strings.Cut(rest[start+1:start+end], ":")
+		rest = rest[start+end+1:]
+
+		switch name {
+		case "date", "time":
+			if arg == "" {
+				arg = map[string]string{"date": "2006-01-02", "time": "150405"}[name]
+			}
+			parts = append(parts, namePart{kind: partTime, text: arg})
+			times++
+			lastTime = len(parts) - 1
+		case "seq":
+			parts = append(parts, namePart{kind: partSeq})
+			seqs++
+		case "slug":
+			parts = append(parts, namePart{kind: partSlug})
+			slugs++
+		default:
+			return nil, fmt.Errorf("unknown placeholder {%s}", name)
+		}
+	}
+
+	switch {
+	case times == 0:
+		return nil, fmt.Errorf("missing {date} or {time}")
+	case slugs != 1:
+		return nil, fmt.Errorf("need exactly one {slug}")
+	case seqs > 1:
+		return nil, fmt.Errorf("more than one {seq}")
+	case seqs == 0:
+		seq := []namePart{{kind: partLiteral, text: "-"}, {kind: partSeq}}
+		parts = append(parts[:lastTime+1], append(seq, parts[lastTime+1:]...)...)
+	}
+
+	// Move the text after the last "/" of the literal before {seq} and
+	// {slug} into their prefix
+	for i := 1; i < len(parts); i++ {
+		if parts[i].kind != partSeq && parts[i].kind != partSlug || parts[i-1].kind != partLiteral {
+			continue
+		}
+		literal := parts[i-1].text
+		cut := strings.LastIndexByte(literal, '/') + 1
+		parts[i-1].text, parts[i].prefix = literal[:cut], literal[cut:]
+	}
+
+	return parts, nil
+}
+
+// layoutElements are the time layout elements usable in filename templates
+// with the text they match, longer elements first
+var layoutElements = []struct{ elem, pattern string }{
+	{"January", `[A-Z][a-z]+`},
+	{"Monday", `[A-Z][a-z]+`},
+	{"2006", `\d{4}`},
+	{"Jan", `[A-Z][a-z]{2}`},
+	{"Mon", `[A-Z][a-z]{2}`},
+	{"002", `\d{3}`},
+	{"01", `\d{2}`}, {"02", `\d{2}`}, {"03", `\d{2}`}, {"04", `\d{2}`},
+	{"05", `\d{2}`}, {"06", `\d{2}`}, {"15", `\d{2}`},
+	{"PM", `[AP]M`}, {"pm", `[ap]m`},
+	{"1", `\d{1,2}`}, {"2", `\d{1,2}`}, {"3", `\d{1,2}`}, {"4", `\d{1,2}`}, {"5", `\d{1,2}`},
+}
+
+// layoutPattern returns a regular expression matching times formatted with layout
+func layoutPattern(layout string) string {
+	var b strings.Builder
+	for layout != "" {
+		matched := false
+		for _, e := range layoutElements {
+			if strings.HasPrefix(layout, e.elem) {
+				b.WriteString(e.pattern)
+				layout = layout[len(e.elem):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteString(regexp.QuoteMeta(layout[:1]))
+			layout = layout[1:]
+		}
+	}
+	return b.String()
+}
+
+// regexp returns the regular expression matching the filenames of f, with a
+// group per time placeholder, sequence number and slug
+func (f *FileNameFormat) regexp() string {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, part := range f.parts {
+		switch part.kind {
+		case partLiteral:
+			b.WriteString(regexp.QuoteMeta(part.text))
+		case partTime:
+			b.WriteString("(" + layoutPattern(part.text) + ")")
+		case partSeq:
+			b.WriteString("(?:" + regexp.QuoteMeta(part.prefix) + "([1-9][0-9]*))?")
+		case partSlug:
+			b.WriteString("(?:" + regexp.QuoteMeta(part.prefix) + "([^/]+))?")
+		}
+	}
+	b.WriteString(regexp.QuoteMeta(f.Ext) + "$")
+	return b.String()
+}
+
+// check verifies that names in f can be parsed back and stay inside the
+// notes directory
+func (f *FileNameFormat) check() error {
+	samples := []NoteName{
+		{Time: time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)},
+		{Time: time.Date(2025, 12, 31, 23, 59, 58, 0, time.Local), Slug: "a" + f.Separator + "b", Seq: 12},
+	}
+	for _, sample := range samples {
+		sample.Ext = f.Ext
+		name := f.Format(sample)
+		if !path.IsAbs(name) && path.Clean(name) == name && !strings.HasPrefix(name, "../") {
+			if _, err := f.Parse(name); err == nil {
+				continue
+			}
+		}
+		return fmt.Errorf("names like %q cannot be read back", name)
+	}
+	return nil
+}
+
+// Slug returns title cleaned the way f puts it in a filename
+func (f *FileNameFormat) Slug(title string) string {
+	return slugify(title, f.Separator)
+}
+
+// NewName returns the name of a new note with the given title and creation time
+func (f *FileNameFormat) NewName(title string, t time.Time) NoteName {
+	return NoteName{Time: t, Slug: f.Slug(title), Ext: f.Ext}
+}
+
+// Format returns the filename of n, relative to the notes directory and
+// inside the directories of f's layout. It is the inverse of Parse.
+func (f *FileNameFormat) Format(n NoteName) string {
+	return n.Time.Format(layoutDir(f.Layout)) + f.formatName(n)
+}
+
+// Rename returns name, a filename accepted by Parse, with the note renamed
+// to n. The layout directories name is in are kept, so a note is renamed in
+// place even before it is migrated to the layout of f.
+func (f *FileNameFormat) Rename(name string, n NoteName) (string, error) {
+	old, err := f.Parse(name)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(name, f.formatName(old)) + f.formatName(n), nil
+}
+
+// Timestamp returns the name of n without its slug and extension, the part
+// that tells notes apart by when they were created, e.g. 2025-08-16_143045-2
+func (f *FileNameFormat) Timestamp(n NoteName) string {
+	n.Slug, n.Ext = "", ""
+	return f.formatName(n)
+}
+
+// formatName formats n with the template of f, without layout directories
+func (f *FileNameFormat) formatName(n NoteName) string {
+	var b strings.Builder
+	for _, part := range f.parts {
+		switch part.kind {
+		case partLiteral:
+			b.WriteString(part.text)
+		case partTime:
+			b.WriteString(n.Time.Format(part.text))
+		case partSeq:
+			if n.Seq > 0 {
+				b.WriteString(part.prefix + strconv.Itoa(n.Seq))
+			}
+		case partSlug:
+			if n.Slug != "" {
+				b.WriteString(part.prefix + n.Slug)
+			}
+		}
+	}
+	b.WriteString(n.Ext)
+	return b.String()
+}
+
+// Parse parses a filename produced by Format, relative to the notes directory
+// and with "/" separators, back into its timestamp, slug, extension and
+// sequence. Names in the directories of any layout are accepted, so notes
+// are found before they are migrated to the layout of f.
+func (f *FileNameFormat) Parse(name string) (NoteName, error) {
+	var note NoteName
+	err := f.parseInLayouts(name, func(rest string) (time.Time, error) {
+		var err error
+		note, err = f.parseName(rest)
+		return note.Time, err
+	})
+	if err != nil {
+		return NoteName{}, err
+	}
+	return note, nil
+}
+
+// parseName parses a name produced by formatName
+func (f *FileNameFormat) parseName(name string) (NoteName, error) {
+	match := f.pattern.FindStringSubmatch(name)
+	if match == nil {
+		return NoteName{}, fmt.Errorf("not a scratch-note filename: %q", name)
+	}
+
+	note := NoteName{Ext: f.Ext}
+	var layouts, values []string
+	group := 1
+	for _, part := range f.parts {
+		switch part.kind {
+		case partTime:
+			layouts = append(layouts, part.text)
+			values = append(values, match[group])
+		case partSeq:
+			if match[group] != "" {
+				seq, err := strconv.Atoi(match[group])
+				if err != nil {
+					return NoteName{}, fmt.Errorf("not a scratch-note filename: %q has invalid sequence %q", name, match[group])
+				}
+				note.Seq = seq
+			}
+		case partSlug:
+			note.Slug = match[group]
+		default:
+			continue
+		}
+		group++
+	}
+
+	t, err := time.ParseInLocation(strings.Join(layouts, "\n"), strings.Join(values, "\n"), time.Local)
+	if err != nil {
+		return NoteName{}, fmt.Errorf("not a scratch-note filename: %q: %v", name, err)
+	}
+	note.Time = t
+
+	// Reject names that only resemble the format, e.g. "Aug" for "August"
+	if f.formatName(note) != name {
+		return NoteName{}, fmt.Errorf("not a scratch-note filename: %q", name)
+	}
+	return note, nil
+}
+
+// DailyFileName returns the filename of the daily note for the day of t,
+// inside the directories of f's layout
+func (f *FileNameFormat) DailyFileName(t time.Time) string {
+	return t.Format(layoutDir(f.Layout)+DailyLayout) + f.Ext
+}
+
+// ParseDailyFileName parses a filename produced by DailyFileName, in the
+// directories of any layout, and returns the start of its day in the local
+// time zone
+func (f *FileNameFormat) ParseDailyFileName(name string) (time.Time, error) {
+	var day time.Time
+	err := f.parseInLayouts(name, func(rest string) (time.Time, error) {
+		var err error
+		day, err = f.parseDailyName(rest)
+		return day, err
+	})
+	if err != nil {
+		return time.Time{}, fmt.Errorf("not a daily note filename: %q", name)
+	}
+	return day, nil
+}
+
+// parseDailyName parses a daily note filename without layout directories
+func (f *FileNameFormat) parseDailyName(name string) (time.Time, error) {
+	base, found := strings.CutSuffix(name, f.Ext)
+	if !found || len(base) != len(DailyLayout) {
+		return time.Time{}, fmt.Errorf("not a daily note filename: %q", name)
+	}
+
+	t, err := time.ParseInLocation(DailyLayout, base, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("not a daily note filename: %q: %v", name, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,279 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFileNameFormat(t *testing.T) {
+	created := time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local)
+
+	tests := []struct {
+		name      string
+		template  string
+		ext       string
+		separator string
+		note      NoteName
+		expected  string
+	}{
+		{
+			name:     "default",
+			note:     NoteName{Time: created, Slug: "meeting-notes", Seq: 2},
+			expected: "2025-08-16_143045-2_meeting-notes.md",
+		},
+		{
+			name:     "default untitled",
+			note:     NoteName{Time: created},
+			expected: "2025-08-16_143045.md",
+		},
+		{
+			name:     "subdirectory without seq placeholder",
+			template: "{date:2006-01-02}/{time:150405}-{slug}",
+			ext:      "txt",
+			note:     NoteName{Time: created, Slug: "standup", Seq: 3},
+			expected: "2025-08-16/143045-3-standup.txt",
+		},
+		{
+			name:     "subdirectory untitled",
+			template: "{date:2006-01-02}/{time:150405}-{slug}",
+			ext:      ".org",
+			note:     NoteName{Time: created},
+			expected: "2025-08-16/143045.org",
+		},
+		{
+			name:      "slug first with month name",
+			template:  "{slug}{seq}@{date:02Jan2006}T{time}",
+			separator: "_",
+			note:      NoteName{Time: created, Slug: "release_plan"},
+			expected:  "release_plan@16Aug2025T143045.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := NewFileNameFormat(tt.template, tt.ext, tt.separator)
+			if err != nil {
+				t.Fatalf("NewFileNameFormat() unexpected error: %v", err)
+			}
+
+			tt.note.Ext = f.Ext
+			name := f.Format(tt.note)
+			if name != tt.expected {
+				t.Errorf("Format() = %q, want %q", name, tt.expected)
+			}
+
+			parsed, err := f.Parse(name)
+			if err != nil {
+				t.Fatalf("Parse(%q) unexpected error: %v", name, err)
+			}
+			if !parsed.Time.Equal(tt.note.Time) || parsed.Slug != tt.note.Slug || parsed.Seq != tt.note.Seq || parsed.Ext != f.Ext {
+				t.Errorf("Parse(%q) = %+v, want %+v", name, parsed, tt.note)
+			}
+		})
+	}
+}
+
+func TestFileNameFormatSlug(t *testing.T) {
+	f, err := NewFileNameFormat("", "", "_")
+	if err != nil {
+		t.Fatalf("NewFileNameFormat() unexpected error: %v", err)
+	}
+
+	name := f.Format(f.NewName(" my  daily/notes ", time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local)))
+	if name != "2025-08-16_143045_my_daily_notes.md" {
+		t.Errorf("Format(NewName()) = %q", name)
+	}
+}
+
+func TestFileNameFormatRejects(t *testing.T) {
+	f, err := NewFileNameFormat("", ".txt", "")
+	if err != nil {
+		t.Fatalf("NewFileNameFormat() unexpected error: %v", err)
+	}
+	for _, name := range []string{"2025-08-16_143045.md", "2025-08-16_143045-02.txt", "2025-08-16.txt", "notes.txt"} {
+		if _, err := f.Parse(name); err == nil {
+			t.Errorf("Parse(%q) expected error", name)
+		}
+	}
+}
+
+func TestNewFileNameFormatInvalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  string
+		ext       string
+		separator string
+	}{
+		{name: "no time", template: "{slug}"},
+		{name: "no slug", template: "{date}_{time}"},
+		{name: "two slugs", template: "{date}_{slug}_{slug}"},
+		{name: "unknown placeholder", template: "{date}_{title}"},
+		{name: "unclosed placeholder", template: "{date}_{slug"},
+		{name: "space-padded day", template: "{date:Jan_2}_{slug}"},
+		{name: "absolute", template: "/{date}_{slug}"},
+		{name: "parent directory", template: "../{date}_{slug}"},
+		{name: "extension with slash", ext: ".md/x"},
+		{name: "separator with slash", separator: "/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewFileNameFormat(tt.template, tt.ext, tt.separator); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestFileNameFormatLayouts(t *testing.T) {
+	created := time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local)
+	note := NoteName{Time: created, Slug: "standup", Ext: ".md"}
+
+	tests := []struct {
+		layout   string
+		expected string
+		daily    string
+	}{
+		{layout: LayoutFlat, expected: "2025-08-16_143045_standup.md", daily: "2025-08-16.md"},
+		{layout: LayoutYearly, expected: "2025/2025-08-16_143045_standup.md", daily: "2025/2025-08-16.md"},
+		{layout: LayoutMonthly, expected: "2025/08/2025-08-16_143045_standup.md", daily: "2025/08/2025-08-16.md"},
+		{layout: LayoutDaily, expected: "2025/08/16/2025-08-16_143045_standup.md", daily: "2025/08/16/2025-08-16.md"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			f, err := DefaultFileNameFormat().WithLayout(tt.layout)
+			if err != nil {
+				t.Fatalf("WithLayout() unexpected error: %v", err)
+			}
+			if name := f.Format(note); name != tt.expected {
+				t.Errorf("Format() = %q, want %q", name, tt.expected)
+			}
+			if name := f.DailyFileName(created); name != tt.daily {
+				t.Errorf("DailyFileName() = %q, want %q", name, tt.daily)
+			}
+
+			// Notes of every layout are recognised, whatever the layout of f
+			for _, other := range tests {
+				parsed, err := f.Parse(other.expected)
+				if err != nil || !parsed.Time.Equal(created) || parsed.Slug != "standup" {
+					t.Errorf("Parse(%q) = %+v, %v", other.expected, parsed, err)
+				}
+				if _, err := f.ParseDailyFileName(other.daily); err != nil {
+					t.Errorf("ParseDailyFileName(%q) unexpected error: %v", other.daily, err)
+				}
+			}
+		})
+	}
+
+	// Directories must match the date of the note
+	f, _ := DefaultFileNameFormat().WithLayout(LayoutMonthly)
+	for _, name := range []string{"2025/09/2025-08-16_143045.md", "2024/2025-08-16_143045.md", "notes/2025-08-16_143045.md"} {
+		if _, err := f.Parse(name); err == nil {
+			t.Errorf("Parse(%q) expected error", name)
+		}
+	}
+
+	if _, err := DefaultFileNameFormat().WithLayout("weekly"); err == nil {
+		t.Error("WithLayout(\"weekly\") expected error")
+	}
+}
+
+func TestFileNameFormatRename(t *testing.T) {
+	created := time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local)
+	renamed := NoteName{Time: created, Slug: "retro", Ext: ".md"}
+
+	tests := []struct {
+		template string
+		name     string
+		expected string
+	}{
+		{DefaultFileNameTemplate, "2025-08-16_143045-2_standup.md", "2025-08-16_143045_retro.md"},
+		{DefaultFileNameTemplate, "2025/08/2025-08-16_143045_standup.md", "2025/08/2025-08-16_143045_retro.md"},
+		{"{date:2006-01-02}/{time:150405}-{slug}", "2025-08-16/143045-standup.md", "2025-08-16/143045-retro.md"},
+	}
+
+	for _, tt := range tests {
+		f, err := NewFileNameFormat(tt.template, "", "")
+		if err != nil {
+			t.Fatalf("NewFileNameFormat(%q) unexpected error: %v", tt.template, err)
+		}
+		got, err := f.Rename(tt.name, renamed)
+		if err != nil || got != tt.expected {
+			t.Errorf("Rename(%q) = %q, %v; want %q", tt.name, got, err, tt.expected)
+		}
+	}
+
+	if _, err := DefaultFileNameFormat().Rename("notes.txt", renamed); err == nil {
+		t.Error("Rename() of a name that is not a note expected error")
+	}
+}
+
+func TestFileNameFormatTimestamp(t *testing.T) {
+	created := time.Date(2025, 8, 16, 14, 30, 45, 0, time.Local)
+
+	tests := []struct {
+		template string
+		name     NoteName
+		expected string
+	}{
+		{DefaultFileNameTemplate, NoteName{Time: created, Slug: "standup", Ext: ".md"}, "2025-08-16_143045"},
+		{DefaultFileNameTemplate, NoteName{Time: created, Slug: "standup", Ext: ".md", Seq: 2}, "2025-08-16_143045-2"},
+		{"{date:2006-01-02}/{time:150405}-{slug}", NoteName{Time: created, Slug: "standup", Ext: ".md"}, "2025-08-16/143045"},
+	}
+
+	for _, tt := range tests {
+		f, err := NewFileNameFormat(tt.template, "", "")
+		if err != nil {
+			t.Fatalf("NewFileNameFormat(%q) unexpected error: %v", tt.template, err)
+		}
+		if got := f.Timestamp(tt.name); got != tt.expected {
+			t.Errorf("Timestamp(%+v) = %q, want %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func FuzzFileNameFormatRoundTrip(f *testing.F) {
+	monthly, err := DefaultFileNameFormat().WithLayout(LayoutMonthly)
+	if err != nil {
+		f.Fatalf("WithLayout() unexpected error: %v", err)
+	}
+	custom, err := NewFileNameFormat("{date:20060102}/{slug}_{time:150405}", ".txt", "_")
+	if err != nil {
+		f.Fatalf("NewFileNameFormat() unexpected error: %v", err)
+	}
+	formats := []*FileNameFormat{DefaultFileNameFormat(), monthly, custom}
+
+	f.Add("", int64(1755354645), uint16(0))
+	f.Add("meeting notes", int64(1755354645), uint16(2))
+	f.Add("notes/with\\special:chars", int64(0), uint16(0))
+	f.Add("v1.2 release_2", int64(253402300799), uint16(12))
+	f.Add("-2_tricky", int64(1755354645), uint16(1))
+	f.Add("...", int64(-62135596800), uint16(0))
+
+	f.Fuzz(func(t *testing.T, title string, sec int64, seq uint16) {
+		// Restrict to years 0001-9999, which format as four digits
+		const minSec, maxSec = -62135596800, 253402300799
+		if sec < minSec || sec > maxSec {
+			sec = minSec + (sec%(maxSec-minSec)+(maxSec-minSec))%(maxSec-minSec)
+		}
+		created := time.Unix(sec, 0).In(time.Local)
+
+		for _, format := range formats {
+			n := format.NewName(title, created)
+			n.Seq = int(seq)
+			name := format.Format(n)
+
+			parsed, err := format.Parse(name)
+			if err != nil {
+				t.Fatalf("Parse(Format(%+v)) = %q: %v", n, name, err)
+			}
+			if !parsed.Time.Equal(created) || parsed.Slug != n.Slug || parsed.Ext != format.Ext || parsed.Seq != n.Seq {
+				t.Errorf("Parse(%q) = %+v, want %+v", name, parsed, n)
+			}
+			if got := format.Format(parsed); got != name {
+				t.Errorf("Format(Parse(%q)) = %q", name, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,31 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// lockTimeout bounds how long LockFile waits for a stale lock file
+const lockTimeout = 10 * time.Second
+
+// LockFile takes an exclusive lock on f, waiting for other holders, and
+// returns a function releasing it. Without flock, the lock is a ".lock" file
+// next to f created exclusively.
+func LockFile(f *os.File) (func() error, error) {
+	lockPath := f.Name() + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		lock, err := os.OpenFile(lockPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if err == nil {
+			lock.Close()
+			return func() error { return os.Remove(lockPath) }, nil
+		}
+		if !os.IsExist(err) || time.Now().After(deadline) {
+			return nil, fmt.Errorf("failed to lock %s: %v", f.Name(), err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
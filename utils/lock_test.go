@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLockFileExcludesOtherHolders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "note.md")
+
+	open := func() *os.File {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+		if err != nil {
+			t.Fatalf("Failed to open file: %v", err)
+		}
+		t.Cleanup(func() { f.Close() })
+		return f
+	}
+
+	unlock, err := LockFile(open())
+	if err != nil {
+		t.Fatalf("LockFile() unexpected error: %v", err)
+	}
+
+	acquired := make(chan func() error)
+	go func() {
+		unlockSecond, err := LockFile(open())
+		if err != nil {
+			t.Errorf("Second LockFile() unexpected error: %v", err)
+		}
+		acquired <- unlockSecond
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Second LockFile() should wait for the first lock to be released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	if err := unlock(); err != nil {
+		t.Fatalf("unlock() unexpected error: %v", err)
+	}
+
+	select {
+	case unlockSecond := <-acquired:
+		if unlockSecond != nil {
+			unlockSecond()
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Second LockFile() should succeed once the lock is released")
+	}
+}
@@ -0,0 +1,28 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// LockFile takes an exclusive advisory lock on f, waiting for other holders,
+// and returns a function releasing it
+func LockFile(f *os.File) (func() error, error) {
+	for {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX)
+		if err == syscall.EINTR {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock %s: %v", f.Name(), err)
+		}
+		break
+	}
+
+	return func() error {
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+	}, nil
+}
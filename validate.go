@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"scratch-note/config"
+	"scratch-note/utils"
+)
+
+// ValidateConfig checks the values of a configuration beyond their
+// types: the notes directory must be writable or creatable, the editor must
+// be on PATH, and the filename format, layout and default template must be
+// usable. Templates are looked up in templatesDir.
+func ValidateConfig(src *config.Source, templatesDir string) []config.Problem {
+	cfg := src.Config
+	var problems []config.Problem
+
+	if strings.TrimSpace(cfg.ScratchNoteDir) == "" {
+		problems = append(problems, src.Problem("scratch_note_dir", "scratch_note_dir is not set"))
+	} else if err := checkWritableDir(config.ExpandPath(cfg.ScratchNoteDir)); err != nil {
+		problems = append(problems, src.Problem("scratch_note_dir", "scratch_note_dir: %v", err))
+	}
+
+	if err := validateEditor(ResolveEditor(cfg.Editor)); err != nil {
+		problems = append(problems, src.Problem("editor", "editor: %v", err))
+	}
+
+	format, err := utils.NewFileNameFormat(cfg.FilenameTemplate, "", "")
+	if err != nil {
+		problems = append(problems, src.Problem("filename_template", "filename_template: %v", err))
+	}
+	if _, err := utils.NewFileNameFormat("", cfg.FilenameExtension, ""); err != nil {
+		problems = append(problems, src.Problem("filename_extension", "filename_extension: %v", err))
+	}
+	if _, err := utils.NewFileNameFormat("", "", cfg.FilenameSeparator); err != nil {
+		problems = append(problems, src.Problem("filename_separator", "filename_separator: %v", err))
+	}
+	if format == nil {
+		format = utils.DefaultFileNameFormat()
+	}
+	if _, err := format.WithLayout(cfg.Layout); err != nil {
+		problems = append(problems, src.Problem("layout", "layout: %v", err))
+	}
+
+	if cfg.DefaultTemplate != "" {
+		if _, err := LoadTemplate(templatesDir, cfg.DefaultTemplate); err != nil {
+			problems = append(problems, src.Problem("default_template", "default_template: %v", err))
+		}
+	}
+
+	return problems
+}
+
+// checkWritableDir checks that notes can be written to dir, or that dir can
+// be created when it does not exist yet
+func checkWritableDir(dir string) error {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		// Created on first use, so the nearest existing parent must be writable
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return fmt.Errorf("%s does not exist", dir)
+		}
+		if err := checkWritableDir(parent); err != nil {
+			return fmt.Errorf("%s does not exist and cannot be created: %v", dir, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+
+	probe, err := os.CreateTemp(dir, ".scratch-note-probe-*")
+	if err != nil {
+		return fmt.Errorf("%s is not writable", dir)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
+// CheckConfig loads the configuration layers and validates the result and
+// every notebook, writing every problem to w, one per line with where it was set. Problems
+// are reported file by file in line order, those without a position last. It
+// returns the number of problems found.
+func CheckConfig(layers config.Layers, templatesDir string, w io.Writer) (int, error) {
+	src, problems, err := config.LoadLayers(layers)
+	if err != nil {
+		return 0, err
+	}
+	problems = append(problems, ValidateConfig(src, templatesDir)...)
+
+	// Check the other notebooks too, reporting settings they share once
+	seen := map[string]bool{}
+	for _, p := range problems {
+		seen[p.String()] = true
+	}
+	for _, name := range src.Config.NotebookNames() {
+		if name == src.Config.Notebook {
+			continue
+		}
+		notebook, err := src.Select(name)
+		if err != nil {
+			continue
+		}
+		for _, p := range ValidateConfig(notebook, templatesDir) {
+			if !seen[p.String()] {
+				seen[p.String()] = true
+				problems = append(problems, p)
+			}
+		}
+	}
+
+	files := map[string]int{}
+	for _, p := range problems {
+		if _, ok := files[p.Path]; !ok && p.Line > 0 {
+			files[p.Path] = len(files)
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool {
+		a, b := problems[i], problems[j]
+		if (a.Line == 0) != (b.Line == 0) {
+			return b.Line == 0
+		}
+		if a.Line == 0 {
+			return false
+		}
+		if a.Path != b.Path {
+			return files[a.Path] < files[b.Path]
+		}
+		return a.Line < b.Line || a.Line == b.Line && a.Column < b.Column
+	})
+
+	for _, p := range problems {
+		fmt.Fprintln(w, p)
+	}
+	return len(problems), nil
+}
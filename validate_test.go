@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"scratch-note/config"
+)
+
+func TestCheckConfigFile(t *testing.T) {
+	fakeEditorOnPath(t, "nano")
+	dir := t.TempDir()
+	notFile := filepath.Join(dir, "file")
+	if err := os.WriteFile(notFile, nil, 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	configPath := filepath.Join(dir, "config.yaml")
+	content := strings.Join([]string{
+		"scratch_note_dir: " + notFile,
+		"editor: nvim --wait",
+		"scratch-note_dir: ~/notes",
+		"layout: weekly",
+		"filename_template: \"{date}\"",
+		"default_template: missing",
+	}, "\n") + "\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	var buf bytes.Buffer
+	count, err := CheckConfig(config.Layers{UserPath: configPath}, filepath.Join(dir, "templates"), &buf)
+	if err != nil {
+		t.Fatalf("CheckConfig() unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if count != 6 || len(lines) != 6 {
+		t.Fatalf("CheckConfig() found %d problems, want 6:\n%s", count, buf.String())
+	}
+	expected := []string{
+		":1:19: scratch_note_dir: " + notFile + " is not a directory",
+		`:2:9: editor: editor "nvim" was not found on PATH`,
+		`:3:1: unknown key "scratch-note_dir" (did you mean "scratch_note_dir"?)`,
+		`:4:9: layout: invalid layout "weekly"`,
+		":5:20: filename_template: invalid filename template",
+		":6:19: default_template: template not found: missing",
+	}
+	for i, want := range expected {
+		if !strings.HasPrefix(lines[i], configPath+want) {
+			t.Errorf("Problem %d = %q, want prefix %q", i+1, lines[i], configPath+want)
+		}
+	}
+}
+
+func TestValidateConfigValid(t *testing.T) {
+	fakeEditorOnPath(t, "nano")
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	content := "scratch_note_dir: " + filepath.Join(dir, "notes", "new") + "\neditor: nano\nlayout: monthly\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	var buf bytes.Buffer
+	count, err := CheckConfig(config.Layers{UserPath: configPath}, dir, &buf)
+	if err != nil || count != 0 {
+		t.Errorf("CheckConfig() = %d, %v; want no problems:\n%s", count, err, buf.String())
+	}
+}
+
+func TestCheckConfigNotebooks(t *testing.T) {
+	fakeEditorOnPath(t, "nano")
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	content := strings.Join([]string{
+		"scratch_note_dir: notes",
+		"editor: nano",
+		"notebooks:",
+		"  work:",
+		"    scratch_note_dir: work",
+		"  client:",
+		"    scratch_note_dir: client",
+		"    layout: weekly",
+	}, "\n") + "\n"
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// Notebooks other than the selected one are checked as well
+	var buf bytes.Buffer
+	count, err := CheckConfig(config.Layers{UserPath: configPath, Overrides: []config.Override{{Key: "notebook", Value: "work", Flag: "--notebook"}}}, dir, &buf)
+	if err != nil {
+		t.Fatalf("CheckConfig() unexpected error: %v", err)
+	}
+	if count != 1 || !strings.HasPrefix(buf.String(), configPath+`:8:13: layout: invalid layout "weekly"`) {
+		t.Errorf("CheckConfig() found %d problems:\n%s", count, buf.String())
+	}
+}